@@ -8,6 +8,8 @@
 
 package duct
 
+import "reflect"
+
 // Abstract syntax tree (AST) of the computation defined by morphism
 type Ast interface {
 	Apply(depth int, v Visitor) error
@@ -20,9 +22,17 @@ type Visitor interface {
 	OnMap
 	OnFrom
 	OnYield
+	OnFork
+	OnMerge
+	OnZip
+	OnFilter
+	OnSwitch
+	OnFold
+	OnBatch
+	OnGroupBy
 }
 
-// Visits root morphism
+// Visits root morphism and branches of fork, merge, zip and switch
 type OnMorphism interface {
 	OnEnterMorphism(depth int, node AstSeq) error
 	OnLeaveMorphism(depth int, node AstSeq) error
@@ -49,19 +59,83 @@ type OnYield interface {
 	OnLeaveYield(depth int, node AstYield) error
 }
 
+// Visits fan-out of morphism into branches
+type OnFork interface {
+	OnEnterFork(depth int, node AstFork) error
+	OnLeaveFork(depth int, node AstFork) error
+}
+
+// Visits fan-out and fan-in of morphism branches into single one
+type OnMerge interface {
+	OnEnterMerge(depth int, node AstMerge) error
+	OnLeaveMerge(depth int, node AstMerge) error
+}
+
+// Visits fan-out and fan-in of morphism branches into pairs
+type OnZip interface {
+	OnEnterZip(depth int, node AstZip) error
+	OnLeaveZip(depth int, node AstZip) error
+}
+
+// Visits filter of morphism elements
+type OnFilter interface {
+	OnEnterFilter(depth int, node AstFilter) error
+	OnLeaveFilter(depth int, node AstFilter) error
+}
+
+// Visits routing of morphism elements to branches
+type OnSwitch interface {
+	OnEnterSwitch(depth int, node AstSwitch) error
+	OnLeaveSwitch(depth int, node AstSwitch) error
+}
+
+// Visits reduction of morphism elements with monoid
+type OnFold interface {
+	OnEnterFold(depth int, node AstFold) error
+	OnLeaveFold(depth int, node AstFold) error
+}
+
+// Visits windowing of morphism elements into batches
+type OnBatch interface {
+	OnEnterBatch(depth int, node AstBatch) error
+	OnLeaveBatch(depth int, node AstBatch) error
+}
+
+// Visits grouping of morphism elements by key
+type OnGroupBy interface {
+	OnEnterGroupBy(depth int, node AstGroupBy) error
+	OnLeaveGroupBy(depth int, node AstGroupBy) error
+}
+
 // Empty abstract syntax tree visitor
 type AstVisitor struct{}
 
-func (AstVisitor) OnEnterMorphism(depth int, node AstSeq) error { return nil }
-func (AstVisitor) OnLeaveMorphism(depth int, node AstSeq) error { return nil }
-func (AstVisitor) OnEnterSeq(depth int, node AstSeq) error      { return nil }
-func (AstVisitor) OnLeaveSeq(depth int, node AstSeq) error      { return nil }
-func (AstVisitor) OnEnterMap(depth int, node AstMap) error      { return nil }
-func (AstVisitor) OnLeaveMap(depth int, node AstMap) error      { return nil }
-func (AstVisitor) OnEnterFrom(depth int, node AstFrom) error    { return nil }
-func (AstVisitor) OnLeaveFrom(depth int, node AstFrom) error    { return nil }
-func (AstVisitor) OnEnterYield(depth int, node AstYield) error  { return nil }
-func (AstVisitor) OnLeaveYield(depth int, node AstYield) error  { return nil }
+func (AstVisitor) OnEnterMorphism(depth int, node AstSeq) error    { return nil }
+func (AstVisitor) OnLeaveMorphism(depth int, node AstSeq) error    { return nil }
+func (AstVisitor) OnEnterSeq(depth int, node AstSeq) error         { return nil }
+func (AstVisitor) OnLeaveSeq(depth int, node AstSeq) error         { return nil }
+func (AstVisitor) OnEnterMap(depth int, node AstMap) error         { return nil }
+func (AstVisitor) OnLeaveMap(depth int, node AstMap) error         { return nil }
+func (AstVisitor) OnEnterFrom(depth int, node AstFrom) error       { return nil }
+func (AstVisitor) OnLeaveFrom(depth int, node AstFrom) error       { return nil }
+func (AstVisitor) OnEnterYield(depth int, node AstYield) error     { return nil }
+func (AstVisitor) OnLeaveYield(depth int, node AstYield) error     { return nil }
+func (AstVisitor) OnEnterFork(depth int, node AstFork) error       { return nil }
+func (AstVisitor) OnLeaveFork(depth int, node AstFork) error       { return nil }
+func (AstVisitor) OnEnterMerge(depth int, node AstMerge) error     { return nil }
+func (AstVisitor) OnLeaveMerge(depth int, node AstMerge) error     { return nil }
+func (AstVisitor) OnEnterZip(depth int, node AstZip) error         { return nil }
+func (AstVisitor) OnLeaveZip(depth int, node AstZip) error         { return nil }
+func (AstVisitor) OnEnterFilter(depth int, node AstFilter) error   { return nil }
+func (AstVisitor) OnLeaveFilter(depth int, node AstFilter) error   { return nil }
+func (AstVisitor) OnEnterSwitch(depth int, node AstSwitch) error   { return nil }
+func (AstVisitor) OnLeaveSwitch(depth int, node AstSwitch) error   { return nil }
+func (AstVisitor) OnEnterFold(depth int, node AstFold) error       { return nil }
+func (AstVisitor) OnLeaveFold(depth int, node AstFold) error       { return nil }
+func (AstVisitor) OnEnterBatch(depth int, node AstBatch) error     { return nil }
+func (AstVisitor) OnLeaveBatch(depth int, node AstBatch) error     { return nil }
+func (AstVisitor) OnEnterGroupBy(depth int, node AstGroupBy) error { return nil }
+func (AstVisitor) OnLeaveGroupBy(depth int, node AstGroupBy) error { return nil }
 
 //------------------------------------------------------------------------------
 
@@ -69,6 +143,7 @@ func (AstVisitor) OnLeaveYield(depth int, node AstYield) error  { return nil }
 type AstFrom struct {
 	Type   string
 	Source any
+	Attrs  Attributes
 }
 
 func (node AstFrom) Apply(depth int, v Visitor) error {
@@ -87,6 +162,7 @@ func (node AstFrom) Apply(depth int, v Visitor) error {
 type AstYield struct {
 	Type   string
 	Target any
+	Attrs  Attributes
 }
 
 // Void type to emulate empty type ø
@@ -104,10 +180,14 @@ func (node AstYield) Apply(depth int, v Visitor) error {
 
 //------------------------------------------------------------------------------
 
-// AST element representing transformation ƒ: A ⟼ B
+// AST element representing transformation ƒ: A ⟼ B. The failure policy of
+// transformation is defined by Retry and Catch, both are optional.
 type AstMap struct {
 	TypeA, TypeB string
 	F            any
+	Retry        *RetryPolicy
+	Catch        any
+	Attrs        Attributes
 }
 
 func (node AstMap) Apply(depth int, v Visitor) error {
@@ -122,11 +202,195 @@ func (node AstMap) Apply(depth int, v Visitor) error {
 
 //------------------------------------------------------------------------------
 
+// AST element for fan-out of morphism into branches ƒ: A ⟼ (ø, ..., ø).
+// Each branch is a morphism 𝑚: A ⟼ ø, terminated by Yield.
+type AstFork struct {
+	Type     string
+	Branches []*AstSeq
+}
+
+func (node AstFork) Apply(depth int, v Visitor) error {
+	if err := v.OnEnterFork(depth, node); err != nil {
+		return err
+	}
+	if err := applyBranches(depth, v, node.Branches); err != nil {
+		return err
+	}
+	if err := v.OnLeaveFork(depth, node); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AST element for fan-out and fan-in of morphism branches ƒ: A ⟼ (B, ..., B) ⟼ B.
+// Each branch is a morphism 𝑚: A ⟼ B, results of branches are merged.
+type AstMerge struct {
+	TypeA, TypeB string
+	Branches     []*AstSeq
+}
+
+func (node AstMerge) Apply(depth int, v Visitor) error {
+	if err := v.OnEnterMerge(depth, node); err != nil {
+		return err
+	}
+	if err := applyBranches(depth, v, node.Branches); err != nil {
+		return err
+	}
+	if err := v.OnLeaveMerge(depth, node); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AST element for fan-out and fan-in of morphism branches ƒ: A ⟼ (B, C) ⟼ B × C.
+// Results of branches are combined into Pair, which reflect type is Pair.
+type AstZip struct {
+	TypeA, TypeB string
+	Pair         reflect.Type
+	Branches     []*AstSeq
+}
+
+func (node AstZip) Apply(depth int, v Visitor) error {
+	if err := v.OnEnterZip(depth, node); err != nil {
+		return err
+	}
+	if err := applyBranches(depth, v, node.Branches); err != nil {
+		return err
+	}
+	if err := v.OnLeaveZip(depth, node); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AST element for filtering elements by predicate ƒ: A ⟼ A.
+type AstFilter struct {
+	Type string
+	F    any
+}
+
+func (node AstFilter) Apply(depth int, v Visitor) error {
+	if err := v.OnEnterFilter(depth, node); err != nil {
+		return err
+	}
+	if err := v.OnLeaveFilter(depth, node); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AST element for routing of elements to branches ƒ: A ⟼ (B | ... | B).
+// Each element is routed to the branch of first matching case, elements not
+// matching any case are dropped.
+type AstSwitch struct {
+	TypeA, TypeB string
+	Cases        []AstCase
+}
+
+// Case of switch, it matches elements either by predicate F or by type.
+// The case w/o predicate matches elements of the type, the case of switch
+// input type matches all elements.
+type AstCase struct {
+	Type   string
+	F      any
+	Branch *AstSeq
+}
+
+func (node AstSwitch) Apply(depth int, v Visitor) error {
+	if err := v.OnEnterSwitch(depth, node); err != nil {
+		return err
+	}
+	for _, c := range node.Cases {
+		if err := c.Branch.Apply(depth+1, v); err != nil {
+			return err
+		}
+	}
+	if err := v.OnLeaveSwitch(depth, node); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Match checks if the case matches the type of element x. The case with
+// predicate always matches, the predicate is evaluated by interpreter.
+func (node AstSwitch) Match(c AstCase, x any) bool {
+	return c.F != nil || c.Type == node.TypeA || c.Type == TypeOfValue(x)
+}
+
+//------------------------------------------------------------------------------
+
+// AST element for reduction of elements with monoid ƒ: A ⟼ A. Elements of
+// the context (e.g. stream) are combined into the single value.
+type AstFold struct {
+	Type   string
+	Monoid any
+}
+
+func (node AstFold) Apply(depth int, v Visitor) error {
+	if err := v.OnEnterFold(depth, node); err != nil {
+		return err
+	}
+	if err := v.OnLeaveFold(depth, node); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AST element for windowing of elements into batches ƒ: A ⟼ []A. Elements
+// of the context (e.g. stream) are batched by Size, the last batch might be
+// incomplete. Batches are slices, which reflect type is Slice.
+type AstBatch struct {
+	Type  string
+	Size  int
+	Slice reflect.Type
+}
+
+func (node AstBatch) Apply(depth int, v Visitor) error {
+	if err := v.OnEnterBatch(depth, node); err != nil {
+		return err
+	}
+	if err := v.OnLeaveBatch(depth, node); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AST element for grouping of elements by key ƒ: A ⟼ map[K][]A. Elements of
+// the context (e.g. stream) are grouped into the single value, the key is
+// computed by function F.
+type AstGroupBy struct {
+	TypeA, TypeB string
+	F            any
+}
+
+func (node AstGroupBy) Apply(depth int, v Visitor) error {
+	if err := v.OnEnterGroupBy(depth, node); err != nil {
+		return err
+	}
+	if err := v.OnLeaveGroupBy(depth, node); err != nil {
+		return err
+	}
+	return nil
+}
+
+func applyBranches(depth int, v Visitor, branches []*AstSeq) error {
+	for _, b := range branches {
+		if err := b.Apply(depth+1, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
 // AST element representing morphism, sequence of transformations 𝑚: A ⟼ B ⟼ ... ⟼ F
 type AstSeq struct {
 	Root     bool
 	Deferred bool
 	Seq      []Ast
+	// Target of failed elements, defined for morphism only
+	DeadLetter any
 }
 
 func (n AstSeq) Apply(depth int, v Visitor) error {
@@ -159,48 +423,86 @@ func (n AstSeq) Apply(depth int, v Visitor) error {
 	return nil
 }
 
-func (f *AstSeq) unit() bool {
+// AST is persistent, the morphism is extended by path copying. Operations
+// below return new sequence, nodes outside of the path to the open context
+// are shared with the original sequence, which is never modified.
+
+// copy of sequence with nodes
+func (f *AstSeq) with(seq []Ast) *AstSeq {
+	c := *f
+	c.Seq = seq
+	return &c
+}
+
+// copy of sequence with the last node replaced
+func (f *AstSeq) withLast(n Ast) *AstSeq {
+	seq := make([]Ast, len(f.Seq))
+	copy(seq, f.Seq)
+	seq[len(seq)-1] = n
+	return f.with(seq)
+}
+
+// closes the open context, the root is never closed, it returns false
+// if the sequence has no open context
+func (f *AstSeq) unit() (*AstSeq, bool) {
 	if !f.Deferred {
-		return false
+		return f, false
 	}
 
-	if len(f.Seq) == 0 {
-		if !f.Root {
-			f.Deferred = false
+	if len(f.Seq) > 0 {
+		if v, ok := f.Seq[len(f.Seq)-1].(*AstSeq); ok {
+			if seq, ok := v.unit(); ok {
+				return f.withLast(seq), true
+			}
 		}
-		return true
 	}
 
-	switch v := f.Seq[len(f.Seq)-1].(type) {
-	case *AstSeq:
-		if ok := v.unit(); ok {
-			return true
-		}
+	if f.Root {
+		return f, false
+	}
+
+	c := f.with(f.Seq)
+	c.Deferred = false
+	return c, true
+}
+
+// last node of open context
+func (f *AstSeq) last() Ast {
+	if len(f.Seq) == 0 {
+		return nil
 	}
 
-	if !f.Root {
-		f.Deferred = false
+	x := f.Seq[len(f.Seq)-1]
+	if seq, ok := x.(*AstSeq); ok && seq.Deferred {
+		return seq.last()
 	}
-	return true
+	return x
 }
 
-func (f *AstSeq) append(n Ast) bool {
-	if !f.Deferred {
-		return false
+// replaces the last node of open context, see last
+func (f *AstSeq) replace(n Ast) *AstSeq {
+	x := f.Seq[len(f.Seq)-1]
+	if seq, ok := x.(*AstSeq); ok && seq.Deferred && len(seq.Seq) > 0 {
+		return f.withLast(seq.replace(n))
 	}
+	return f.withLast(n)
+}
 
-	if len(f.Seq) == 0 {
-		f.Seq = append(f.Seq, n)
-		return true
+// appends node to the open context
+func (f *AstSeq) append(n Ast) (*AstSeq, bool) {
+	if !f.Deferred {
+		return f, false
 	}
 
-	switch v := f.Seq[len(f.Seq)-1].(type) {
-	case *AstSeq:
-		if ok := v.append(n); ok {
-			return true
+	if len(f.Seq) > 0 {
+		if v, ok := f.Seq[len(f.Seq)-1].(*AstSeq); ok {
+			if seq, ok := v.append(n); ok {
+				return f.withLast(seq), true
+			}
 		}
 	}
 
-	f.Seq = append(f.Seq, n)
-	return true
+	seq := make([]Ast, len(f.Seq), len(f.Seq)+1)
+	copy(seq, f.Seq)
+	return f.with(append(seq, n)), true
 }
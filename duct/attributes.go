@@ -0,0 +1,53 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"time"
+)
+
+// Attributes of source, transformer or target are hints to interpreters about
+// its concurrency and deployment (e.g. number of workers, required resources).
+// Attributes do not change semantic of the morphism, interpreters might ignore
+// them. Zero value of attribute means it is not defined.
+type Attributes struct {
+	// Human readable name of the node
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Number of concurrent workers
+	Parallelism int `json:"parallelism,omitempty" yaml:"parallelism,omitempty"`
+	// Timeout of single invocation
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Memory required by the node, in megabytes
+	Memory int `json:"memory,omitempty" yaml:"memory,omitempty"`
+	// Number of elements processed as single batch
+	Batch int `json:"batch,omitempty" yaml:"batch,omitempty"`
+	// Extension attributes specific to the interpreter
+	Ext map[string]string `json:"ext,omitempty" yaml:"ext,omitempty"`
+}
+
+// IsZero returns true if none of attributes is defined.
+func (a Attributes) IsZero() bool {
+	return a.Name == "" && a.Parallelism == 0 && a.Timeout == 0 && a.Memory == 0 && a.Batch == 0 && len(a.Ext) == 0
+}
+
+// With attaches attributes to the source or target of category A.
+//
+//	duct.L1[A](src).With(duct.Attributes{Name: "events"})
+func (t T[A]) With(attrs Attributes) T[A] {
+	t.attrs = attrs
+	return t
+}
+
+// With attaches attributes to the transformer 𝑓: A ⟼ B.
+//
+//	duct.L2[A, B](f).With(duct.Attributes{Parallelism: 8, Timeout: time.Second})
+func (f F[A, B]) With(attrs Attributes) F[A, B] {
+	f.attrs = attrs
+	return f
+}
@@ -0,0 +1,113 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/it/v2"
+)
+
+type attributes struct {
+	duct.AstVisitor
+	seq []duct.Attributes
+}
+
+func (v *attributes) OnEnterFrom(depth int, node duct.AstFrom) error {
+	v.seq = append(v.seq, node.Attrs)
+	return nil
+}
+
+func (v *attributes) OnEnterMap(depth int, node duct.AstMap) error {
+	v.seq = append(v.seq, node.Attrs)
+	return nil
+}
+
+func (v *attributes) OnEnterYield(depth int, node duct.AstYield) error {
+	v.seq = append(v.seq, node.Attrs)
+	return nil
+}
+
+func TestAttributes(t *testing.T) {
+	in := duct.Attributes{Name: "in", Batch: 10}
+	fn := duct.Attributes{Name: "itoa", Parallelism: 8, Timeout: time.Second, Memory: 128}
+	eg := duct.Attributes{Name: "out", Ext: map[string]string{"queue": "events"}}
+
+	m := duct.Yield(duct.L1[string](nil).With(eg),
+		duct.Join(
+			duct.Retry(duct.RetryPolicy{Attempts: 2}, itoa.With(fn)),
+			duct.Join(atoi, duct.From(duct.L1[string](nil).With(in))),
+		),
+	)
+
+	t.Run("IsZero", func(t *testing.T) {
+		it.Then(t).Should(
+			it.True(duct.Attributes{}.IsZero()),
+			it.True(duct.Attributes{Ext: map[string]string{}}.IsZero()),
+		).ShouldNot(
+			it.True(fn.IsZero()),
+			it.True(eg.IsZero()),
+		)
+	})
+
+	t.Run("Visitor", func(t *testing.T) {
+		v := &attributes{}
+		it.Then(t).Should(
+			it.Nil(m.Apply(v)),
+			it.Equal(len(v.seq), 4),
+			it.Equal(v.seq[0].Name, "in"),
+			it.Equal(v.seq[0].Batch, 10),
+			it.True(v.seq[1].IsZero()),
+			it.Equal(v.seq[2].Name, "itoa"),
+			it.Equal(v.seq[2].Parallelism, 8),
+			it.Equal(v.seq[2].Timeout, time.Second),
+			it.Equal(v.seq[2].Memory, 128),
+			it.Equal(v.seq[3].Ext["queue"], "events"),
+		)
+	})
+
+	t.Run("Codec", func(t *testing.T) {
+		r := duct.NewRegistry()
+		it.Then(t).Should(
+			it.Nil(r.Register("itoa", itoa)),
+			it.Nil(r.Register("atoi", atoi)),
+		)
+
+		data, err := duct.Marshal(m, r)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.Contains(string(data), `"parallelism": 8`)),
+		)
+
+		c, err := duct.Unmarshal[string, duct.Void](data, r)
+		it.Then(t).Should(it.Nil(err))
+
+		v := &attributes{}
+		it.Then(t).Should(
+			it.Nil(c.Apply(v)),
+			it.Equal(len(v.seq), 4),
+			it.Equal(v.seq[0].Name, "in"),
+			it.True(v.seq[1].IsZero()),
+			it.Equal(v.seq[2].Parallelism, 8),
+			it.Equal(v.seq[3].Ext["queue"], "events"),
+		)
+	})
+
+	t.Run("Rewrite", func(t *testing.T) {
+		r := duct.Rewrite(duct.Join(atoi.With(fn), duct.Join(itoa, duct.From(src))), duct.FuseMap)
+
+		s, _ := duct.Render(r, duct.FormatText)
+		it.Then(t).Should(
+			it.Equal(strings.Count(s, "ƒ:"), 3),
+		)
+	})
+}
@@ -0,0 +1,379 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Kinds of serialized AST nodes
+const (
+	KindMorphism = "morphism"
+	KindSeq      = "seq"
+	KindFrom     = "from"
+	KindMap      = "map"
+	KindYield    = "yield"
+	KindFork     = "fork"
+	KindMerge    = "merge"
+	KindZip      = "zip"
+	KindFilter   = "filter"
+	KindSwitch   = "switch"
+	KindCase     = "case"
+	KindFold     = "fold"
+	KindBatch    = "batch"
+	KindGroupBy  = "groupBy"
+)
+
+// Spec is the serializable form of AST node. Go values (sources, transformers,
+// predicates and targets) are stored as symbolic references resolved through
+// the Registry. Spec is annotated for JSON and YAML encoders.
+type Spec struct {
+	Kind     string `json:"kind" yaml:"kind"`
+	Type     string `json:"type,omitempty" yaml:"type,omitempty"`
+	TypeA    string `json:"typeA,omitempty" yaml:"typeA,omitempty"`
+	TypeB    string `json:"typeB,omitempty" yaml:"typeB,omitempty"`
+	Ref      string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	Deferred bool   `json:"deferred,omitempty" yaml:"deferred,omitempty"`
+	Seq      []Spec `json:"seq,omitempty" yaml:"seq,omitempty"`
+	Branches []Spec `json:"branches,omitempty" yaml:"branches,omitempty"`
+	Size     int    `json:"size,omitempty" yaml:"size,omitempty"`
+	// Failure policy of transformer and morphism
+	Retry      *RetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty"`
+	Catch      string       `json:"catch,omitempty" yaml:"catch,omitempty"`
+	DeadLetter string       `json:"deadLetter,omitempty" yaml:"deadLetter,omitempty"`
+	// Attributes of source, transformer and target
+	Attrs *Attributes `json:"attrs,omitempty" yaml:"attrs,omitempty"`
+}
+
+// Encode morphism 𝑚: A ⟼ B into the serializable form. All values used by
+// the morphism must be registered, except nil ones.
+func Encode[A, B any](m Morphism[A, B], r *Registry) (Spec, error) {
+	return encodeSeq(m.code, r)
+}
+
+// Decode morphism 𝑚: A ⟼ B from the serializable form, resolving symbolic
+// references through the registry. It fails if the morphism is not 𝑚: A ⟼ B
+// or resolved values do not match declared types, see Validate.
+func Decode[A, B any](spec Spec, r *Registry) (Morphism[A, B], error) {
+	if spec.Kind != KindMorphism {
+		return Morphism[A, B]{}, fmt.Errorf("spec of %s is not morphism", spec.Kind)
+	}
+
+	code, err := decodeSeq(spec, r)
+	if err != nil {
+		return Morphism[A, B]{}, err
+	}
+
+	if len(code.Seq) > 0 {
+		if from, ok := code.Seq[0].(*AstFrom); ok && from.Type != TypeOf[A]() {
+			return Morphism[A, B]{}, fmt.Errorf("morphism of %s does not match %s", from.Type, TypeOf[A]())
+		}
+	}
+
+	v := &validator{}
+	if t := v.seq(nil, code, "", true, true); t != TypeOf[B]() && !(t == "ø" && TypeOf[B]() == TypeOf[Void]()) {
+		return Morphism[A, B]{}, fmt.Errorf("morphism ⟼ %s does not match %s", t, TypeOf[B]())
+	}
+
+	// Note: nil values are not registered, they are not validated
+	m := Morphism[A, B]{code: code}
+	if err := validate(m, true); err != nil {
+		return Morphism[A, B]{}, err
+	}
+
+	return m, nil
+}
+
+// Marshal morphism 𝑚: A ⟼ B into JSON
+func Marshal[A, B any](m Morphism[A, B], r *Registry) ([]byte, error) {
+	spec, err := Encode(m, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// Unmarshal morphism 𝑚: A ⟼ B from JSON
+func Unmarshal[A, B any](data []byte, r *Registry) (Morphism[A, B], error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return Morphism[A, B]{}, err
+	}
+
+	return Decode[A, B](spec, r)
+}
+
+//------------------------------------------------------------------------------
+
+func encodeRef(r *Registry, x any) (string, error) {
+	if x == nil {
+		return "", nil
+	}
+
+	name, has := r.Symbol(x)
+	if !has {
+		return "", fmt.Errorf("symbol of %T is not registered", x)
+	}
+
+	return name, nil
+}
+
+func encodeAttrs(attrs Attributes) *Attributes {
+	if attrs.IsZero() {
+		return nil
+	}
+	return &attrs
+}
+
+func encodeSeq(node *AstSeq, r *Registry) (Spec, error) {
+	kind := KindSeq
+	if node.Root {
+		kind = KindMorphism
+	}
+
+	spec := Spec{Kind: kind, Deferred: node.Deferred, Seq: make([]Spec, len(node.Seq))}
+
+	dlq, err := encodeRef(r, node.DeadLetter)
+	if err != nil {
+		return Spec{}, err
+	}
+	spec.DeadLetter = dlq
+
+	for i, x := range node.Seq {
+		s, err := encodeNode(x, r)
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.Seq[i] = s
+	}
+
+	return spec, nil
+}
+
+func encodeBranches(branches []*AstSeq, r *Registry) ([]Spec, error) {
+	seq := make([]Spec, len(branches))
+	for i, b := range branches {
+		s, err := encodeSeq(b, r)
+		if err != nil {
+			return nil, err
+		}
+		seq[i] = s
+	}
+
+	return seq, nil
+}
+
+func encodeNode(node Ast, r *Registry) (spec Spec, err error) {
+	switch n := node.(type) {
+	case *AstSeq:
+		return encodeSeq(n, r)
+	case *AstFrom:
+		spec = Spec{Kind: KindFrom, Type: n.Type, Attrs: encodeAttrs(n.Attrs)}
+		spec.Ref, err = encodeRef(r, n.Source)
+	case *AstMap:
+		spec = Spec{Kind: KindMap, TypeA: n.TypeA, TypeB: n.TypeB, Retry: n.Retry, Attrs: encodeAttrs(n.Attrs)}
+		if spec.Catch, err = encodeRef(r, n.Catch); err != nil {
+			return Spec{}, err
+		}
+		spec.Ref, err = encodeRef(r, n.F)
+	case *AstYield:
+		spec = Spec{Kind: KindYield, Type: n.Type, Attrs: encodeAttrs(n.Attrs)}
+		spec.Ref, err = encodeRef(r, n.Target)
+	case *AstFilter:
+		spec = Spec{Kind: KindFilter, Type: n.Type}
+		spec.Ref, err = encodeRef(r, n.F)
+	case *AstFork:
+		spec = Spec{Kind: KindFork, Type: n.Type}
+		spec.Branches, err = encodeBranches(n.Branches, r)
+	case *AstMerge:
+		spec = Spec{Kind: KindMerge, TypeA: n.TypeA, TypeB: n.TypeB}
+		spec.Branches, err = encodeBranches(n.Branches, r)
+	case *AstZip:
+		spec = Spec{Kind: KindZip, TypeA: n.TypeA, TypeB: n.TypeB}
+		if spec.Ref, err = encodeRef(r, n.Pair); err != nil {
+			return Spec{}, err
+		}
+		spec.Branches, err = encodeBranches(n.Branches, r)
+	case *AstSwitch:
+		spec = Spec{Kind: KindSwitch, TypeA: n.TypeA, TypeB: n.TypeB, Branches: make([]Spec, len(n.Cases))}
+		for i, c := range n.Cases {
+			cs := Spec{Kind: KindCase, Type: c.Type}
+			if cs.Ref, err = encodeRef(r, c.F); err != nil {
+				return Spec{}, err
+			}
+
+			b, err := encodeSeq(c.Branch, r)
+			if err != nil {
+				return Spec{}, err
+			}
+			cs.Branches = []Spec{b}
+			spec.Branches[i] = cs
+		}
+	case *AstFold:
+		spec = Spec{Kind: KindFold, Type: n.Type}
+		spec.Ref, err = encodeRef(r, n.Monoid)
+	case *AstBatch:
+		spec = Spec{Kind: KindBatch, Type: n.Type, Size: n.Size}
+		spec.Ref, err = encodeRef(r, n.Slice)
+	case *AstGroupBy:
+		spec = Spec{Kind: KindGroupBy, TypeA: n.TypeA, TypeB: n.TypeB}
+		spec.Ref, err = encodeRef(r, n.F)
+	default:
+		return Spec{}, fmt.Errorf("unsupported node %T", node)
+	}
+
+	if err != nil {
+		return Spec{}, err
+	}
+
+	return spec, nil
+}
+
+//------------------------------------------------------------------------------
+
+func decodeRef(r *Registry, name string) (any, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	x, has := r.Lookup(name)
+	if !has {
+		return nil, fmt.Errorf("symbol %s is not registered", name)
+	}
+
+	return x, nil
+}
+
+func decodeAttrs(attrs *Attributes) Attributes {
+	if attrs == nil {
+		return Attributes{}
+	}
+	return *attrs
+}
+
+func decodeSeq(spec Spec, r *Registry) (*AstSeq, error) {
+	if spec.Kind != KindSeq && spec.Kind != KindMorphism {
+		return nil, fmt.Errorf("spec of %s is not sequence", spec.Kind)
+	}
+
+	dlq, err := decodeRef(r, spec.DeadLetter)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &AstSeq{
+		Root:       spec.Kind == KindMorphism,
+		Deferred:   spec.Deferred,
+		Seq:        make([]Ast, len(spec.Seq)),
+		DeadLetter: dlq,
+	}
+	for i, s := range spec.Seq {
+		x, err := decodeNode(s, r)
+		if err != nil {
+			return nil, err
+		}
+		node.Seq[i] = x
+	}
+
+	return node, nil
+}
+
+func decodeBranches(specs []Spec, r *Registry) ([]*AstSeq, error) {
+	seq := make([]*AstSeq, len(specs))
+	for i, s := range specs {
+		b, err := decodeSeq(s, r)
+		if err != nil {
+			return nil, err
+		}
+		seq[i] = b
+	}
+
+	return seq, nil
+}
+
+func decodeNode(spec Spec, r *Registry) (Ast, error) {
+	switch spec.Kind {
+	case KindMorphism, KindSeq:
+		return decodeSeq(spec, r)
+	case KindFrom:
+		x, err := decodeRef(r, spec.Ref)
+		return &AstFrom{Type: spec.Type, Source: x, Attrs: decodeAttrs(spec.Attrs)}, err
+	case KindMap:
+		x, err := decodeRef(r, spec.Ref)
+		if err != nil {
+			return nil, err
+		}
+		h, err := decodeRef(r, spec.Catch)
+		return &AstMap{TypeA: spec.TypeA, TypeB: spec.TypeB, F: x, Retry: spec.Retry, Catch: h, Attrs: decodeAttrs(spec.Attrs)}, err
+	case KindYield:
+		x, err := decodeRef(r, spec.Ref)
+		return &AstYield{Type: spec.Type, Target: x, Attrs: decodeAttrs(spec.Attrs)}, err
+	case KindFilter:
+		x, err := decodeRef(r, spec.Ref)
+		return &AstFilter{Type: spec.Type, F: x}, err
+	case KindFork:
+		seq, err := decodeBranches(spec.Branches, r)
+		return &AstFork{Type: spec.Type, Branches: seq}, err
+	case KindMerge:
+		seq, err := decodeBranches(spec.Branches, r)
+		return &AstMerge{TypeA: spec.TypeA, TypeB: spec.TypeB, Branches: seq}, err
+	case KindZip:
+		x, err := decodeRef(r, spec.Ref)
+		if err != nil {
+			return nil, err
+		}
+		pair, ok := x.(reflect.Type)
+		if !ok {
+			return nil, fmt.Errorf("symbol %s is not type", spec.Ref)
+		}
+		seq, err := decodeBranches(spec.Branches, r)
+		return &AstZip{TypeA: spec.TypeA, TypeB: spec.TypeB, Pair: pair, Branches: seq}, err
+	case KindSwitch:
+		node := &AstSwitch{TypeA: spec.TypeA, TypeB: spec.TypeB, Cases: make([]AstCase, len(spec.Branches))}
+		for i, cs := range spec.Branches {
+			if cs.Kind != KindCase || len(cs.Branches) != 1 {
+				return nil, fmt.Errorf("spec of %s is not case", cs.Kind)
+			}
+
+			f, err := decodeRef(r, cs.Ref)
+			if err != nil {
+				return nil, err
+			}
+
+			b, err := decodeSeq(cs.Branches[0], r)
+			if err != nil {
+				return nil, err
+			}
+
+			node.Cases[i] = AstCase{Type: cs.Type, F: f, Branch: b}
+		}
+		return node, nil
+	case KindFold:
+		x, err := decodeRef(r, spec.Ref)
+		return &AstFold{Type: spec.Type, Monoid: x}, err
+	case KindBatch:
+		x, err := decodeRef(r, spec.Ref)
+		if err != nil {
+			return nil, err
+		}
+		slice, ok := x.(reflect.Type)
+		if !ok {
+			return nil, fmt.Errorf("symbol %s is not type", spec.Ref)
+		}
+		return &AstBatch{Type: spec.Type, Size: spec.Size, Slice: slice}, nil
+	case KindGroupBy:
+		x, err := decodeRef(r, spec.Ref)
+		return &AstGroupBy{TypeA: spec.TypeA, TypeB: spec.TypeB, F: x}, err
+	default:
+		return nil, fmt.Errorf("unsupported spec of %s", spec.Kind)
+	}
+}
@@ -0,0 +1,194 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/it/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCodec(t *testing.T) {
+	r := duct.NewRegistry()
+	it.Then(t).Should(
+		it.Nil(r.Register("itoa", itoa)),
+		it.Nil(r.Register("atoi", atoi)),
+		it.Nil(r.Register("dup", dup)),
+		it.Nil(r.Register("odd", odd)),
+		it.Nil(r.Register("pair", reflect.TypeFor[duct.Pair[string, []int]]())),
+		it.Nil(r.Register("upto", upto)),
+		it.Nil(r.Register("sum", sum{})),
+		it.Nil(r.Register("batch", reflect.TypeFor[[]map[bool][]int]())),
+	)
+
+	t.Run("Register", func(t *testing.T) {
+		it.Then(t).ShouldNot(
+			it.Nil(r.Register("itoa", atoi)),
+			it.Nil(r.Register("seq", struct{ Seq []int }{})),
+			it.Nil(r.Register("itoa2", itoa)),
+		)
+	})
+
+	t.Run("RegisterClosure", func(t *testing.T) {
+		r := duct.NewRegistry()
+		add := func(n int) duct.F[int, int] {
+			return duct.L2[int, int](func(x int) (int, error) { return x + n, nil })
+		}
+
+		it.Then(t).Should(
+			it.Nil(r.Register("inc", add(1))),
+			it.Fail(func() error { return r.Register("dec", add(-1)) }).Contain("already registered as inc"),
+		)
+	})
+
+	t.Run("Symbol", func(t *testing.T) {
+		name, has := r.Symbol(itoa)
+		it.Then(t).Should(
+			it.True(has),
+			it.Equal(name, "itoa"),
+		)
+	})
+
+	t.Run("Marshal", func(t *testing.T) {
+		m := duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(src))))
+
+		data, err := duct.Marshal(m, r)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.Contains(string(data), `"ref": "dup"`)),
+		)
+
+		c, err := duct.Unmarshal[int, []string](data, r)
+		it.Then(t).Should(it.Nil(err))
+
+		v, err := duct.Eval(c, 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal("2", "2"),
+		)
+	})
+
+	t.Run("Branches", func(t *testing.T) {
+		m := duct.Zip(duct.Filter(odd, duct.From(src)),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, string] {
+				return duct.Switch(m,
+					duct.When(odd, func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) }),
+				)
+			},
+			func(m duct.Morphism[int, int]) duct.Morphism[int, []int] { return duct.Join(dup, m) },
+		)
+
+		data, err := duct.Marshal(m, r)
+		it.Then(t).Should(it.Nil(err))
+
+		c, err := duct.Unmarshal[int, duct.Pair[string, []int]](data, r)
+		it.Then(t).Should(it.Nil(err))
+
+		v, err := duct.Eval(c, 3)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v.Fst, "3"),
+			it.Seq(v.Snd).Equal(3, 3, 3),
+		)
+	})
+
+	t.Run("Aggregation", func(t *testing.T) {
+		m := duct.Unit(duct.Batch(2, duct.GroupBy(odd, duct.Fold(sum{}, duct.WrapF(duct.Join(upto, duct.From(src)))))))
+
+		data, err := duct.Marshal(m, r)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.Contains(string(data), `"size": 2`)),
+		)
+
+		c, err := duct.Unmarshal[int, [][]map[bool][]int](data, r)
+		it.Then(t).Should(it.Nil(err))
+
+		v, err := duct.Eval(c, 5)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(v), 1),
+			it.Equal(len(v[0]), 1),
+			it.Seq(v[0][0][true]).Equal(15),
+		)
+	})
+
+	t.Run("NotRegistered", func(t *testing.T) {
+		m := duct.Join(duct.L2[int, int](func(x int) (int, error) { return x, nil }), duct.From(src))
+
+		_, err := duct.Marshal(m, r)
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("is not registered"),
+		)
+
+		_, err = duct.Unmarshal[int, int]([]byte(`{"kind": "morphism", "seq": [{"kind": "map", "ref": "f"}]}`), r)
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("symbol f is not registered"),
+		)
+	})
+
+	t.Run("TypeMismatch", func(t *testing.T) {
+		data, err := duct.Marshal(duct.Join(itoa, duct.From(src)), r)
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = duct.Unmarshal[int, int](data, r)
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("morphism ⟼ string does not match int"),
+		)
+
+		_, err = duct.Unmarshal[string, string](data, r)
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("morphism of int does not match string"),
+		)
+	})
+
+	t.Run("SymbolMismatch", func(t *testing.T) {
+		data, err := duct.Marshal(duct.Join(itoa, duct.From(src)), r)
+		it.Then(t).Should(it.Nil(err))
+
+		data = []byte(strings.Replace(string(data), `"ref": "itoa"`, `"ref": "atoi"`, 1))
+		_, err = duct.Unmarshal[int, string](data, r)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Fail(func() error { return err }).Contain("transformer input string does not match int"),
+		)
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		m := duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(src))))
+
+		spec, err := duct.Encode(m, r)
+		it.Then(t).Should(it.Nil(err))
+
+		data, err := yaml.Marshal(spec)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.Contains(string(data), "ref: dup")),
+		)
+
+		var s duct.Spec
+		it.Then(t).Should(it.Nil(yaml.Unmarshal(data, &s)))
+
+		c, err := duct.Decode[int, []string](s, r)
+		it.Then(t).Should(it.Nil(err))
+
+		v, err := duct.Eval(c, 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal("2", "2"),
+		)
+	})
+}
@@ -0,0 +1,283 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Op is the kind of change between morphisms
+type Op int
+
+const (
+	// Node is inserted into the new morphism
+	OpInsert Op = iota
+	// Node is removed from the old morphism
+	OpRemove
+	// Node is changed, it has same kind but different types or values
+	OpChange
+)
+
+// Change of the node between morphisms
+type Change struct {
+	Op Op
+	// Path to node, indexes of node at each level of AST. The path is defined
+	// in the old morphism for removed and changed nodes, in the new one for
+	// inserted nodes.
+	Path []int
+	// Old and new node, nil if the node does not exist in the morphism
+	Old, New Ast
+}
+
+func (c Change) String() string {
+	seq := make([]string, len(c.Path))
+	for i, x := range c.Path {
+		seq[i] = strconv.Itoa(x)
+	}
+	path := "/" + strings.Join(seq, "/")
+
+	switch c.Op {
+	case OpInsert:
+		return fmt.Sprintf("+ %s (%s)", path, signature(c.New))
+	case OpRemove:
+		return fmt.Sprintf("- %s (%s)", path, signature(c.Old))
+	default:
+		return fmt.Sprintf("~ %s (%s) ⟼ (%s)", path, signature(c.Old), signature(c.New))
+	}
+}
+
+// Equal returns true if morphisms are structurally equivalent: they have
+// same kinds of nodes, type signatures and identities of values (sources,
+// transformers, predicates and targets), failure policies and attributes.
+//
+// Note: identity of function is its code pointer, closures of the same
+// function literal are not distinguished.
+func Equal[A, B, C, D any](a Morphism[A, B], b Morphism[C, D]) bool {
+	return equalNode(a.code, b.code)
+}
+
+// Diff returns ordered list of nodes inserted, removed and changed by
+// the morphism b comparing to the morphism a. Nodes are compared as defined
+// by Equal, nested contexts and branches of changed nodes are compared
+// recursively. The list is empty if morphisms are equal.
+func Diff[A, B, C, D any](a Morphism[A, B], b Morphism[C, D]) []Change {
+	d := &differ{}
+	d.node(nil, nil, a.code, b.code)
+	return d.seq
+}
+
+type differ struct {
+	seq []Change
+}
+
+func (d *differ) emit(op Op, path []int, a, b Ast) {
+	d.seq = append(d.seq, Change{Op: op, Path: append([]int{}, path...), Old: a, New: b})
+}
+
+// compares nodes of same kind, pa and pb are paths to nodes in morphisms
+func (d *differ) node(pa, pb []int, a, b Ast) {
+	if !equalHead(a, b) {
+		d.emit(OpChange, pa, a, b)
+		return
+	}
+
+	switch x := a.(type) {
+	case *AstSeq:
+		d.nodes(pa, pb, x.Seq, b.(*AstSeq).Seq)
+	case *AstFork:
+		d.branches(pa, pb, x.Branches, b.(*AstFork).Branches)
+	case *AstMerge:
+		d.branches(pa, pb, x.Branches, b.(*AstMerge).Branches)
+	case *AstZip:
+		d.branches(pa, pb, x.Branches, b.(*AstZip).Branches)
+	case *AstSwitch:
+		y := b.(*AstSwitch)
+		for k := range x.Cases {
+			d.node(append(pa, k), append(pb, k), x.Cases[k].Branch, y.Cases[k].Branch)
+		}
+	}
+}
+
+func (d *differ) branches(pa, pb []int, a, b []*AstSeq) {
+	for k := range a {
+		d.node(append(pa, k), append(pb, k), a[k], b[k])
+	}
+}
+
+// compares sequences of nodes using the longest common subsequence, removed
+// and inserted nodes of same kind in between common ones are changed nodes.
+func (d *differ) nodes(pa, pb []int, a, b []Ast) {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if equalNode(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var (
+		i, j     int
+		del, ins []int
+	)
+
+	flush := func() {
+		n := 0
+		for n < len(del) && n < len(ins) && reflect.TypeOf(a[del[n]]) == reflect.TypeOf(b[ins[n]]) {
+			d.node(append(pa, del[n]), append(pb, ins[n]), a[del[n]], b[ins[n]])
+			n++
+		}
+		for _, k := range del[n:] {
+			d.emit(OpRemove, append(pa, k), a[k], nil)
+		}
+		for _, k := range ins[n:] {
+			d.emit(OpInsert, append(pb, k), nil, b[k])
+		}
+		del, ins = nil, nil
+	}
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && equalNode(a[i], b[j]):
+			flush()
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			del = append(del, i)
+			i++
+		default:
+			ins = append(ins, j)
+			j++
+		}
+	}
+	flush()
+}
+
+//------------------------------------------------------------------------------
+
+// deep equality of nodes
+func equalNode(a, b Ast) bool {
+	if !equalHead(a, b) {
+		return false
+	}
+
+	switch x := a.(type) {
+	case *AstSeq:
+		y := b.(*AstSeq)
+		if len(x.Seq) != len(y.Seq) {
+			return false
+		}
+		for i := range x.Seq {
+			if !equalNode(x.Seq[i], y.Seq[i]) {
+				return false
+			}
+		}
+		return true
+	case *AstFork:
+		return equalBranches(x.Branches, b.(*AstFork).Branches)
+	case *AstMerge:
+		return equalBranches(x.Branches, b.(*AstMerge).Branches)
+	case *AstZip:
+		return equalBranches(x.Branches, b.(*AstZip).Branches)
+	case *AstSwitch:
+		y := b.(*AstSwitch)
+		for k := range x.Cases {
+			if !equalNode(x.Cases[k].Branch, y.Cases[k].Branch) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+func equalBranches(a, b []*AstSeq) bool {
+	for k := range a {
+		if !equalNode(a[k], b[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equality of nodes, excluding nested sequences and branches
+func equalHead(a, b Ast) bool {
+	switch x := a.(type) {
+	case *AstSeq:
+		y, ok := b.(*AstSeq)
+		return ok && x.Root == y.Root && x.Deferred == y.Deferred && sameValue(x.DeadLetter, y.DeadLetter)
+	case *AstFrom:
+		y, ok := b.(*AstFrom)
+		return ok && x.Type == y.Type && sameValue(x.Source, y.Source) && reflect.DeepEqual(x.Attrs, y.Attrs)
+	case *AstMap:
+		y, ok := b.(*AstMap)
+		return ok && x.TypeA == y.TypeA && x.TypeB == y.TypeB && sameValue(x.F, y.F) &&
+			reflect.DeepEqual(x.Retry, y.Retry) && sameValue(x.Catch, y.Catch) && reflect.DeepEqual(x.Attrs, y.Attrs)
+	case *AstYield:
+		y, ok := b.(*AstYield)
+		return ok && x.Type == y.Type && sameValue(x.Target, y.Target) && reflect.DeepEqual(x.Attrs, y.Attrs)
+	case *AstFork:
+		y, ok := b.(*AstFork)
+		return ok && x.Type == y.Type && len(x.Branches) == len(y.Branches)
+	case *AstMerge:
+		y, ok := b.(*AstMerge)
+		return ok && x.TypeA == y.TypeA && x.TypeB == y.TypeB && len(x.Branches) == len(y.Branches)
+	case *AstZip:
+		y, ok := b.(*AstZip)
+		return ok && x.TypeA == y.TypeA && x.TypeB == y.TypeB && x.Pair == y.Pair && len(x.Branches) == len(y.Branches)
+	case *AstFilter:
+		y, ok := b.(*AstFilter)
+		return ok && x.Type == y.Type && sameValue(x.F, y.F)
+	case *AstSwitch:
+		y, ok := b.(*AstSwitch)
+		if !ok || x.TypeA != y.TypeA || x.TypeB != y.TypeB || len(x.Cases) != len(y.Cases) {
+			return false
+		}
+		for k := range x.Cases {
+			if x.Cases[k].Type != y.Cases[k].Type || !sameValue(x.Cases[k].F, y.Cases[k].F) {
+				return false
+			}
+		}
+		return true
+	case *AstFold:
+		y, ok := b.(*AstFold)
+		return ok && x.Type == y.Type && sameValue(x.Monoid, y.Monoid)
+	case *AstBatch:
+		y, ok := b.(*AstBatch)
+		return ok && x.Type == y.Type && x.Size == y.Size && x.Slice == y.Slice
+	case *AstGroupBy:
+		y, ok := b.(*AstGroupBy)
+		return ok && x.TypeA == y.TypeA && x.TypeB == y.TypeB && sameValue(x.F, y.F)
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// values are same if they have same symbolic identity, see Registry
+func sameValue(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	sa, oka := symbolOf(a)
+	sb, okb := symbolOf(b)
+	if oka && okb {
+		return sa == sb
+	}
+
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,127 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/it/v2"
+)
+
+func changes(seq []duct.Change) []string {
+	out := make([]string, len(seq))
+	for i, x := range seq {
+		out[i] = x.String()
+	}
+	return out
+}
+
+func TestEqual(t *testing.T) {
+	sink := duct.L1[string](func(string) error { return nil })
+	quote := duct.L2[int, string](func(x int) (string, error) { return strconv.Quote(strconv.Itoa(x)), nil })
+
+	t.Run("Equal", func(t *testing.T) {
+		a := duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(src))))
+		b := duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(src))))
+
+		it.Then(t).Should(
+			it.True(duct.Equal(a, b)),
+			it.Equal(len(duct.Diff(a, b)), 0),
+		)
+	})
+
+	t.Run("Codec", func(t *testing.T) {
+		r := duct.NewRegistry()
+		it.Then(t).Should(
+			it.Nil(r.Register("itoa", itoa)),
+			it.Nil(r.Register("dup", dup)),
+		)
+
+		a := duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(src))))
+		data, err := duct.Marshal(a, r)
+		it.Then(t).Should(it.Nil(err))
+
+		b, err := duct.Unmarshal[int, []string](data, r)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(duct.Equal(a, b)),
+		)
+	})
+
+	t.Run("NotEqual", func(t *testing.T) {
+		it.Then(t).ShouldNot(
+			it.True(duct.Equal(duct.Join(itoa, duct.From(src)), duct.Join(quote, duct.From(src)))),
+			it.True(duct.Equal(duct.Join(itoa, duct.From(src)), duct.From(src))),
+			it.True(duct.Equal(
+				duct.Join(duct.Retry(duct.RetryPolicy{Attempts: 3}, itoa), duct.From(src)),
+				duct.Join(itoa, duct.From(src)),
+			)),
+			it.True(duct.Equal(
+				duct.Join(itoa.With(duct.Attributes{Parallelism: 2}), duct.From(src)),
+				duct.Join(itoa, duct.From(src)),
+			)),
+		)
+	})
+
+	t.Run("Insert", func(t *testing.T) {
+		a := duct.Join(itoa, duct.From(src))
+		b := duct.Join(itoa, duct.Filter(odd, duct.From(src)))
+
+		it.Then(t).Should(
+			it.Seq(changes(duct.Diff(a, b))).Equal("+ /1 (ƒ: int ⟼ int)"),
+			it.Seq(changes(duct.Diff(b, a))).Equal("- /1 (ƒ: int ⟼ int)"),
+		)
+	})
+
+	t.Run("Change", func(t *testing.T) {
+		a := duct.Join(atoi, duct.Join(itoa, duct.From(src)))
+		b := duct.Join(atoi, duct.Join(quote, duct.From(src)))
+
+		seq := duct.Diff(a, b)
+		it.Then(t).Should(
+			it.Seq(changes(seq)).Equal("~ /1 (ƒ: int ⟼ string) ⟼ (ƒ: int ⟼ string)"),
+			it.Equal(seq[0].Op, duct.OpChange),
+		)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		a := duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(src))))
+		b := duct.Unit(duct.Join(atoi, duct.LiftF(quote, duct.Join(dup, duct.From(src)))))
+
+		it.Then(t).Should(
+			it.Seq(changes(duct.Diff(a, b))).Equal(
+				"~ /2/0 (ƒ: int ⟼ string) ⟼ (ƒ: int ⟼ string)",
+				"+ /2/1 (ƒ: string ⟼ int)",
+			),
+		)
+	})
+
+	t.Run("Branches", func(t *testing.T) {
+		fork := func(f duct.F[int, string]) duct.Morphism[int, duct.Void] {
+			return duct.Fork(duct.From(src),
+				func(m duct.Morphism[int, int]) duct.Morphism[int, duct.Void] {
+					return duct.Yield(sink, duct.Join(f, m))
+				},
+			)
+		}
+
+		a, b := fork(itoa), fork(quote)
+		it.Then(t).ShouldNot(
+			it.True(duct.Equal(a, b)),
+		)
+
+		seq := duct.Diff(a, b)
+		it.Then(t).Should(
+			it.Equal(len(seq), 1),
+			it.Seq(seq[0].Path).Equal(1, 0, 0),
+		)
+	})
+}
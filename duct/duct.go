@@ -9,22 +9,42 @@
 package duct
 
 import (
+	"fmt"
 	"reflect"
+
+	"github.com/fogfish/golem/pure/monoid"
 )
 
 // Unary type A
-type T[A any] struct{ v any }
+type T[A any] struct {
+	v     any
+	attrs Attributes
+}
 
 // Lifts a value into a unary type A
 func L1[A any](f any) T[A] { return T[A]{v: f} }
 
+func (t T[A]) value() any { return t.v }
+
 // Binary type A, B
-type F[A, B any] struct{ f any }
+type F[A, B any] struct {
+	f     any
+	retry *RetryPolicy
+	attrs Attributes
+}
 
 // Lifts a value into a binary type A, B
 func L2[A, B any](f any) F[A, B] { return F[A, B]{f: f} }
 
+func (f F[A, B]) value() any { return f.f }
+
+// value lifted into unary or binary type
+type lifted interface{ value() any }
+
 // Morphism 𝑚: A ⟼ B is an abstract transformer of category `A` to `B`.
+// Morphism is immutable value, combinators produce new morphism sharing
+// the structure with the original one. It is safe to extend the same morphism
+// in multiple directions.
 type Morphism[A, B any] struct {
 	code *AstSeq
 }
@@ -36,31 +56,31 @@ func (seq Morphism[A, B]) Apply(v Visitor) error {
 
 // From create new morphism 𝑓: ø ⟼ A, binding it with source of category `A`.
 func From[A any](source T[A]) Morphism[A, A] {
-	code := &AstSeq{
-		Root:     true,
-		Deferred: true,
-		Seq:      make([]Ast, 0),
-	}
-
 	in := &AstFrom{
 		Type:   TypeOf[A](),
 		Source: source.v,
+		Attrs:  source.attrs,
+	}
+
+	code := &AstSeq{
+		Root:     true,
+		Deferred: true,
+		Seq:      []Ast{in},
 	}
-	code.append(in)
 
 	return Morphism[A, A]{code: code}
 }
 
 // Compose transformer 𝑓: B ⟼ C with morphism 𝑚: A ⟼ B producing a new morphism 𝑚: A ⟼ C.
 func Join[A, B, C any](f F[B, C], m Morphism[A, B]) Morphism[A, C] {
-	code := m.code
-
 	join := &AstMap{
 		TypeA: TypeOf[B](),
 		TypeB: TypeOf[C](),
 		F:     f.f,
+		Retry: f.retry,
+		Attrs: f.attrs,
 	}
-	code.append(join)
+	code, _ := m.code.append(join)
 
 	return Morphism[A, C]{code: code}
 }
@@ -78,21 +98,21 @@ func Join[A, B, C any](f F[B, C], m Morphism[A, B]) Morphism[A, C] {
 // the monad (e.g. use Unit(Join(g, LiftF(f))) to leave nested context into
 // the morphism 𝑚: A ⟼ []C).
 func LiftF[A, B, C any](f F[B, C], m Morphism[A, []B]) Morphism[A, C] {
-	inner := &AstSeq{
-		Root:     false,
-		Deferred: true,
-		Seq:      make([]Ast, 0),
-	}
-
 	join := &AstMap{
 		TypeA: TypeOf[B](),
 		TypeB: TypeOf[C](),
 		F:     f.f,
+		Retry: f.retry,
+		Attrs: f.attrs,
 	}
-	inner.append(join)
 
-	code := m.code
-	code.append(inner)
+	inner := &AstSeq{
+		Root:     false,
+		Deferred: true,
+		Seq:      []Ast{join},
+	}
+
+	code, _ := m.code.append(inner)
 
 	return Morphism[A, C]{code: code}
 }
@@ -109,8 +129,7 @@ func WrapF[A, B any](m Morphism[A, []B]) Morphism[A, B] {
 		Seq:      make([]Ast, 0),
 	}
 
-	code := m.code
-	code.append(inner)
+	code, _ := m.code.append(inner)
 
 	return Morphism[A, B]{code: code}
 }
@@ -119,38 +138,257 @@ func WrapF[A, B any](m Morphism[A, []B]) Morphism[A, B] {
 // of the morphism 𝑚: A ⟼ []B. It acts as the terminal operation, ensuring
 // that all staged compositions, such as those built with LiftF and WrapF,
 // are fully resolved into a single, consumable form.
+//
+// Unit of the morphism without open context lifts each element of B into
+// the singleton []B, it is equivalent to Batch(1, 𝑚).
 func Unit[A, B any](m Morphism[A, B]) Morphism[A, []B] {
-	code := m.code
-	code.unit()
+	code, ok := m.code.unit()
+	if !ok {
+		code, _ = m.code.append(&AstBatch{
+			Type:  TypeOf[B](),
+			Size:  1,
+			Slice: reflect.TypeOf([]B{}),
+		})
+	}
 
 	return Morphism[A, []B]{code: code}
 }
 
 // Yield results of 𝑚: A ⟼ B binding it with target of category `B`.
 func Yield[A, B any](target T[B], m Morphism[A, B]) Morphism[A, Void] {
-	code := m.code
-
 	eg := &AstYield{
 		Type:   TypeOf[B](),
 		Target: target.v,
+		Attrs:  target.attrs,
+	}
+	code, _ := m.code.append(eg)
+
+	return Morphism[A, Void]{code: code}
+}
+
+// Compose the fragment 𝑓: B ⟼ C with morphism 𝑚: A ⟼ B producing a new
+// morphism 𝑚: A ⟼ C. Stages of fragment are spliced into the open context of
+// the morphism, if any, therefore the fragment is applied to each element of B.
+// The fragment is reusable across multiple compositions. The dead letter target
+// of fragment is used unless the morphism defines its own one.
+// It panics if the fragment has source.
+func Compose[A, B, C any](m Morphism[A, B], f Morphism[B, C]) Morphism[A, C] {
+	if len(f.code.Seq) > 0 && isFrom(f.code.Seq[0]) {
+		panic(fmt.Errorf("morphism %s ⟼ %s is not fragment", ShortTypeOf[B](), ShortTypeOf[C]()))
+	}
+
+	code := m.code
+	for _, x := range f.code.Seq {
+		code, _ = code.append(x)
+	}
+
+	if code.DeadLetter == nil && f.code.DeadLetter != nil {
+		code = code.with(code.Seq)
+		code.DeadLetter = f.code.DeadLetter
+	}
+
+	return Morphism[A, C]{code: code}
+}
+
+// Pair of values produced by Zip
+type Pair[A, B any] struct {
+	Fst A
+	Snd B
+}
+
+// Fragment creates new source-less morphism 𝑚: A ⟼ A. Fragments are reusable
+// building blocks, spliced into other morphisms by Compose. Branches of fork,
+// merge, zip and switch are fragments as well.
+//
+//	var Parse = duct.Join(atoi, duct.Fragment[string]())
+//
+//	duct.Compose(duct.From(src), Parse)
+func Fragment[A any]() Morphism[A, A] {
+	return Morphism[A, A]{
+		code: &AstSeq{
+			Root:     true,
+			Deferred: true,
+			Seq:      make([]Ast, 0),
+		},
 	}
-	code.append(eg)
+}
+
+// Fork the morphism 𝑚: A ⟼ B into branches, each of them is terminated by
+// Yield. It allows to feed multiple targets from single source.
+//
+//	duct.Fork(m,
+//		func(m duct.Morphism[B, B]) duct.Morphism[B, duct.Void] { return duct.Yield(b, m) },
+//		func(m duct.Morphism[B, B]) duct.Morphism[B, duct.Void] { return duct.Yield(c, duct.Join(f, m)) },
+//	)
+func Fork[A, B any](m Morphism[A, B], branches ...func(Morphism[B, B]) Morphism[B, Void]) Morphism[A, Void] {
+	fork := &AstFork{
+		Type:     TypeOf[B](),
+		Branches: make([]*AstSeq, len(branches)),
+	}
+	for i, f := range branches {
+		fork.Branches[i] = f(Fragment[B]()).code
+	}
+	code, _ := m.code.append(fork)
 
 	return Morphism[A, Void]{code: code}
 }
 
-// TypeOf returns normalized name of the type T.
+// Merge forks the morphism 𝑚: A ⟼ B into branches 𝑚: B ⟼ C and joins
+// results of branches back into the morphism 𝑚: A ⟼ C.
+func Merge[A, B, C any](m Morphism[A, B], branches ...func(Morphism[B, B]) Morphism[B, C]) Morphism[A, C] {
+	merge := &AstMerge{
+		TypeA:    TypeOf[B](),
+		TypeB:    TypeOf[C](),
+		Branches: make([]*AstSeq, len(branches)),
+	}
+	for i, f := range branches {
+		merge.Branches[i] = f(Fragment[B]()).code
+	}
+	code, _ := m.code.append(merge)
+
+	return Morphism[A, C]{code: code}
+}
+
+// Zip forks the morphism 𝑚: A ⟼ B into two branches 𝑚: B ⟼ C and 𝑚: B ⟼ D,
+// combining results of branches into the morphism 𝑚: A ⟼ C × D.
+func Zip[A, B, C, D any](
+	m Morphism[A, B],
+	fc func(Morphism[B, B]) Morphism[B, C],
+	fd func(Morphism[B, B]) Morphism[B, D],
+) Morphism[A, Pair[C, D]] {
+	zip := &AstZip{
+		TypeA:    TypeOf[B](),
+		TypeB:    TypeOf[Pair[C, D]](),
+		Pair:     reflect.TypeOf(Pair[C, D]{}),
+		Branches: []*AstSeq{fc(Fragment[B]()).code, fd(Fragment[B]()).code},
+	}
+	code, _ := m.code.append(zip)
+
+	return Morphism[A, Pair[C, D]]{code: code}
+}
+
+// Filter elements of morphism 𝑚: A ⟼ B by predicate 𝑓: B ⟼ bool.
+func Filter[A, B any](pred F[B, bool], m Morphism[A, B]) Morphism[A, B] {
+	filter := &AstFilter{
+		Type: TypeOf[B](),
+		F:    pred.f,
+	}
+	code, _ := m.code.append(filter)
+
+	return Morphism[A, B]{code: code}
+}
+
+// Case of Switch, it routes elements of B to the branch 𝑚: B ⟼ C.
+type Case[B, C any] struct{ c AstCase }
+
+// When routes elements of B matching the predicate to the branch 𝑚: B ⟼ C.
+func When[B, C any](pred F[B, bool], branch func(Morphism[B, B]) Morphism[B, C]) Case[B, C] {
+	return Case[B, C]{
+		c: AstCase{
+			Type:   TypeOf[B](),
+			F:      pred.f,
+			Branch: branch(Fragment[B]()).code,
+		},
+	}
+}
+
+// Is routes elements of B, which are of type T, to the branch 𝑚: T ⟼ C.
+// It is usable for routing of events by type, where B is an interface.
+func Is[B, T, C any](branch func(Morphism[T, T]) Morphism[T, C]) Case[B, C] {
+	return Case[B, C]{
+		c: AstCase{
+			Type:   TypeOf[T](),
+			Branch: branch(Fragment[T]()).code,
+		},
+	}
+}
+
+// Otherwise routes all elements of B to the branch 𝑚: B ⟼ C, use it as
+// the last case of Switch.
+func Otherwise[B, C any](branch func(Morphism[B, B]) Morphism[B, C]) Case[B, C] {
+	return Is[B, B](branch)
+}
+
+// Switch routes each element of morphism 𝑚: A ⟼ B to the branch of first
+// matching case, elements not matching any case are dropped.
+//
+//	duct.Switch(m,
+//		duct.Is[Event, Click](func(m duct.Morphism[Click, Click]) duct.Morphism[Click, string] { ... }),
+//		duct.Otherwise(func(m duct.Morphism[Event, Event]) duct.Morphism[Event, string] { ... }),
+//	)
+func Switch[A, B, C any](m Morphism[A, B], cases ...Case[B, C]) Morphism[A, C] {
+	sw := &AstSwitch{
+		TypeA: TypeOf[B](),
+		TypeB: TypeOf[C](),
+		Cases: make([]AstCase, len(cases)),
+	}
+	for i, c := range cases {
+		sw.Cases[i] = c.c
+	}
+	code, _ := m.code.append(sw)
+
+	return Morphism[A, C]{code: code}
+}
+
+// Fold reduces elements of morphism 𝑚: A ⟼ B with the monoid into the single
+// value of B. The morphism context is reduced, which is either the stream of
+// elements or the nested context built by LiftF and WrapF. The monoid
+// should be identifiable value (e.g. struct type) to be registered for
+// serialization.
+func Fold[A, B any](m monoid.Monoid[B], mo Morphism[A, B]) Morphism[A, B] {
+	fold := &AstFold{
+		Type:   TypeOf[B](),
+		Monoid: m,
+	}
+	code, _ := mo.code.append(fold)
+
+	return Morphism[A, B]{code: code}
+}
+
+// Batch windows elements of morphism 𝑚: A ⟼ B into batches of the size.
+// The last batch is emitted when the context is completed, it might be
+// incomplete.
+func Batch[A, B any](size int, m Morphism[A, B]) Morphism[A, []B] {
+	batch := &AstBatch{
+		Type:  TypeOf[B](),
+		Size:  size,
+		Slice: reflect.TypeOf([]B{}),
+	}
+	code, _ := m.code.append(batch)
+
+	return Morphism[A, []B]{code: code}
+}
+
+// GroupBy groups elements of morphism 𝑚: A ⟼ B by the key 𝑓: B ⟼ K. Groups
+// are emitted as the single value when the context is completed.
+func GroupBy[A, B any, K comparable](key F[B, K], m Morphism[A, B]) Morphism[A, map[K][]B] {
+	group := &AstGroupBy{
+		TypeA: TypeOf[B](),
+		TypeB: TypeOf[map[K][]B](),
+		F:     key.f,
+	}
+	code, _ := m.code.append(group)
+
+	return Morphism[A, map[K][]B]{code: code}
+}
+
+// TypeOf returns normalized name of the type T. The name is package qualified
+// identity of the type, collision-free for every kind of types (e.g.
+// github.com/fogfish/golem/duct.Pair[int,string]). Use ShortTypeOf for display.
 func TypeOf[T any]() string {
 	return typeName(reflect.TypeOf(new(T)).Elem())
 }
 
-func typeName(t reflect.Type) string {
-	switch t.Kind() {
-	case reflect.Ptr:
-		return "*" + typeName(t.Elem())
-	case reflect.Slice:
-		return "[]" + typeName(t.Elem())
-	default:
-		return t.Name()
+// TypeOfValue returns normalized name of the dynamic type of value x.
+func TypeOfValue(x any) string {
+	if x == nil {
+		return ""
 	}
+	return typeName(reflect.TypeOf(x))
+}
+
+// ShortTypeOf returns short name of the type T for display, package paths are
+// omitted (e.g. Pair[int,string]). The short name is not collision-free.
+func ShortTypeOf[T any]() string {
+	return ShortType(TypeOf[T]())
 }
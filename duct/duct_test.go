@@ -126,14 +126,47 @@ var tests = map[string]T{
 }
 
 func TestTypeOf(t *testing.T) {
+	const pkg = "github.com/fogfish/golem/duct_test."
+
+	it.Then(t).Should(
+		it.Equal(duct.TypeOf[A](), pkg+"A"),
+		it.Equal(duct.TypeOf[*A](), "*"+pkg+"A"),
+		it.Equal(duct.TypeOf[[]A](), "[]"+pkg+"A"),
+		it.Equal(duct.TypeOf[[]*A](), "[]*"+pkg+"A"),
+		it.Equal(duct.TypeOf[[][]A](), "[][]"+pkg+"A"),
+		it.Equal(duct.TypeOf[[][]*A](), "[][]*"+pkg+"A"),
+		it.Equal(duct.TypeOf[it.Check](), "github.com/fogfish/it/v2.Check"),
+		it.Equal(duct.TypeOf[int](), "int"),
+		it.Equal(duct.TypeOf[error](), "error"),
+		it.Equal(duct.TypeOf[[3]A](), "[3]"+pkg+"A"),
+		it.Equal(duct.TypeOf[map[string]A](), "map[string]"+pkg+"A"),
+		it.Equal(duct.TypeOf[<-chan A](), "<-chan "+pkg+"A"),
+		it.Equal(duct.TypeOf[chan<- A](), "chan<- "+pkg+"A"),
+		it.Equal(duct.TypeOf[chan (<-chan int)](), "chan (<-chan int)"),
+		it.Equal(duct.TypeOf[func(A, ...int) (B, error)](), "func("+pkg+"A, ...int) ("+pkg+"B, error)"),
+		it.Equal(duct.TypeOf[struct {
+			X A `json:"x"`
+		}](), "struct { X "+pkg+`A "json:\"x\"" }`),
+		it.Equal(duct.TypeOf[any](), "interface {}"),
+		it.Equal(duct.TypeOf[interface{ Apply(duct.Visitor) error }](), "interface { Apply(github.com/fogfish/golem/duct.Visitor) error }"),
+		it.Equal(duct.TypeOf[duct.Pair[A, int]](), "github.com/fogfish/golem/duct.Pair["+pkg+"A,int]"),
+	)
+}
+
+func TestShortTypeOf(t *testing.T) {
 	it.Then(t).Should(
-		it.Equal(duct.TypeOf[A](), "A"),
-		it.Equal(duct.TypeOf[*A](), "*A"),
-		it.Equal(duct.TypeOf[[]A](), "[]A"),
-		it.Equal(duct.TypeOf[[]*A](), "[]*A"),
-		it.Equal(duct.TypeOf[[][]A](), "[][]A"),
-		it.Equal(duct.TypeOf[[][]*A](), "[][]*A"),
-		it.Equal(duct.TypeOf[it.Check](), "Check"),
+		it.Equal(duct.ShortTypeOf[A](), "A"),
+		it.Equal(duct.ShortTypeOf[*A](), "*A"),
+		it.Equal(duct.ShortTypeOf[[]A](), "[]A"),
+		it.Equal(duct.ShortTypeOf[[][]*A](), "[][]*A"),
+		it.Equal(duct.ShortTypeOf[it.Check](), "Check"),
+		it.Equal(duct.ShortTypeOf[map[string]A](), "map[string]A"),
+		it.Equal(duct.ShortTypeOf[func(A) (B, error)](), "func(A) (B, error)"),
+		it.Equal(duct.ShortTypeOf[func(...A)](), "func(...A)"),
+		it.Equal(duct.ShortTypeOf[duct.Pair[A, int]](), "Pair[A,int]"),
+		it.Equal(duct.ShortTypeOf[struct {
+			X A `json:"x.y"`
+		}](), `struct { X A "json:\"x.y\"" }`),
 	)
 }
 
@@ -148,6 +181,108 @@ func TestDuct(t *testing.T) {
 	}
 }
 
+func TestCompose(t *testing.T) {
+	for _, spec := range [][2]T{
+		{
+			duct.Join(fBC, duct.Join(fAB, duct.From(a))),
+			duct.Compose(duct.From(a), duct.Join(fBC, duct.Join(fAB, duct.Fragment[A]()))),
+		},
+		{
+			duct.LiftF(fBC, duct.Join(fABs, duct.From(a))),
+			duct.Compose(duct.WrapF(duct.Join(fABs, duct.From(a))), duct.Join(fBC, duct.Fragment[B]())),
+		},
+		{
+			duct.Join(fCsD, duct.Unit(duct.LiftF(fBC, duct.Join(fABs, duct.From(a))))),
+			duct.Compose(duct.Join(fABs, duct.From(a)), duct.Join(fCsD, duct.Unit(duct.LiftF(fBC, duct.Fragment[[]B]())))),
+		},
+		{
+			duct.Unit(duct.Join(fAB, duct.From(a))),
+			duct.Compose(duct.From(a), duct.Unit(duct.Join(fAB, duct.Fragment[A]()))),
+		},
+	} {
+		expect, actual := &printer{}, &printer{}
+		spec[0].Apply(expect)
+		spec[1].Apply(actual)
+
+		it.Then(t).Should(
+			it.Equal(actual.String(), expect.String()),
+		)
+	}
+}
+
+func TestComposeUnit(t *testing.T) {
+	m := duct.Join(fAB, duct.From(a))
+	f := duct.Unit(duct.Join(fBC, duct.Fragment[B]()))
+
+	it.Then(t).Should(
+		it.True(duct.Equal(duct.Unit(m), duct.Batch(1, m))),
+		it.True(duct.Equal(duct.Compose(m, f), duct.Batch(1, duct.Join(fBC, m)))),
+		it.True(duct.Equal(duct.Join(fCsD, f), duct.Join(fCsD, duct.Batch(1, duct.Join(fBC, duct.Fragment[B]()))))),
+		it.True(duct.Equal(
+			duct.Compose(duct.WrapF(duct.Join(fABs, duct.From(a))), f),
+			duct.Batch(1, duct.LiftF(fBC, duct.Join(fABs, duct.From(a)))),
+		)),
+	)
+}
+
+func TestImmutable(t *testing.T) {
+	text := func(m T) string {
+		p := &printer{}
+		m.Apply(p)
+		return p.String()
+	}
+
+	t.Run("Join", func(t *testing.T) {
+		base := duct.Join(fAB, duct.From(a))
+		expect := text(base)
+
+		x := duct.Join(fBC, base)
+		y := duct.Yield(b, base)
+
+		it.Then(t).Should(
+			it.Equal(text(base), expect),
+			it.Equal(text(x), "\n𝑚: ø ⟼ C\n\tƒ: ø ⟼ A\n\tƒ: A ⟼ B\n\tƒ: B ⟼ C\n"),
+			it.Equal(text(y), "\n𝑚: ø ⟼ ø\n\tƒ: ø ⟼ A\n\tƒ: A ⟼ B\n\tƒ: B ⟼ ø\n"),
+		)
+	})
+
+	t.Run("LiftF", func(t *testing.T) {
+		base := duct.LiftF(fBC, duct.Join(fABs, duct.From(a)))
+		expect := text(base)
+
+		x := duct.Unit(base)
+		y := duct.Yield(c, base)
+		z := duct.Join(fCsD, x)
+
+		it.Then(t).Should(
+			it.Equal(text(base), expect),
+			it.Equal(text(x), "\n𝑚: ø ⟼ []C\n\tƒ: ø ⟼ A\n\tƒ: A ⟼ []B\n\t\tƒ: B ⟼ C\n"),
+			it.Equal(text(y), "\n𝑚: ø ⟼ ø\n\tƒ: ø ⟼ A\n\tƒ: A ⟼ []B\n\t\tƒ: B ⟼ C\n\t\tƒ: C ⟼ ø\n"),
+			it.Equal(text(z), "\n𝑚: ø ⟼ D\n\tƒ: ø ⟼ A\n\tƒ: A ⟼ []B\n\t\tƒ: B ⟼ C\n\tƒ: []C ⟼ D\n"),
+		)
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		fail := duct.L2[int, int](func(int) (int, error) { return 0, fmt.Errorf("fail") })
+		base := duct.Join(fail, duct.From(duct.L1[int](nil)))
+
+		x := duct.Catch(duct.L2[error, int](func(error) (int, error) { return -1, nil }), base)
+		y := duct.DeadLetter(duct.L1[duct.Failure](func(duct.Failure) error { return nil }), base)
+
+		_, err := duct.Eval(base, 1)
+		vx, errx := duct.Eval(x, 1)
+		vy, erry := duct.EvalSeq(y, 1)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("fail"),
+			it.Nil(errx),
+			it.Equal(vx, -1),
+			it.Nil(erry),
+			it.Equal(len(vy), 0),
+		)
+	})
+}
+
 type printer struct {
 	duct.AstVisitor
 	strings.Builder
@@ -157,25 +292,25 @@ func (p *printer) OnEnterMorphism(depth int, node duct.AstSeq) error {
 	nt := &typer{}
 	node.Apply(0, nt)
 
-	fmt.Fprintf(p, "\n𝑚: %s ⟼ %s\n", nt.A, nt.B)
+	fmt.Fprintf(p, "\n𝑚: %s ⟼ %s\n", duct.ShortType(nt.A), duct.ShortType(nt.B))
 	return nil
 }
 
 func (p *printer) OnEnterFrom(depth int, node duct.AstFrom) error {
 	s := strings.Repeat("\t", depth)
-	fmt.Fprintf(p, "%sƒ: ø ⟼ %s\n", s, node.Type)
+	fmt.Fprintf(p, "%sƒ: ø ⟼ %s\n", s, duct.ShortType(node.Type))
 	return nil
 }
 
 func (p *printer) OnEnterYield(depth int, node duct.AstYield) error {
 	s := strings.Repeat("\t", depth)
-	fmt.Fprintf(p, "%sƒ: %s ⟼ ø\n", s, node.Type)
+	fmt.Fprintf(p, "%sƒ: %s ⟼ ø\n", s, duct.ShortType(node.Type))
 	return nil
 }
 
 func (p *printer) OnEnterMap(depth int, node duct.AstMap) error {
 	s := strings.Repeat("\t", depth)
-	fmt.Fprintf(p, "%sƒ: %s ⟼ %s\n", s, node.TypeA, node.TypeB)
+	fmt.Fprintf(p, "%sƒ: %s ⟼ %s\n", s, duct.ShortType(node.TypeA), duct.ShortType(node.TypeB))
 	return nil
 }
 
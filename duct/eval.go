@@ -0,0 +1,462 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/fogfish/golem/duct/internal/fn"
+)
+
+// Eval is in-memory synchronous evaluator of the morphism 𝑚: A ⟼ B. It feeds
+// the input value instead of the source, applies transformers func(A) (B, error)
+// and returns the result. Targets, if defined as func(B) error, are called
+// with results. Failure policy (Retry, Catch, DeadLetter) of the morphism is
+// respected. Eval is the reference semantic of morphism, use it for testing.
+//
+// The morphism with open free monadic context (e.g. WrapF w/o Unit) produces
+// sequence of values, use EvalSeq for them.
+func Eval[A, B any](m Morphism[A, B], input A) (B, error) {
+	seq, err := EvalSeq(m, input)
+	if err != nil {
+		return *new(B), err
+	}
+
+	switch len(seq) {
+	case 0:
+		return *new(B), nil
+	case 1:
+		return seq[0], nil
+	default:
+		return *new(B), fmt.Errorf("morphism ⟼ %s produces %d values, use EvalSeq", TypeOf[B](), len(seq))
+	}
+}
+
+// EvalSeq is in-memory synchronous evaluator of the morphism 𝑚: A ⟼ B,
+// it returns all values produced by the morphism, see Eval for details.
+// The morphism is validated before evaluation, see Validate, except that
+// the source and Unit of free monadic context are not required.
+func EvalSeq[A, B any](m Morphism[A, B], input A) ([]B, error) {
+	// Note: the source is not required and free monadic context might be open
+	if err := validate(m, true); err != nil {
+		return nil, err
+	}
+
+	ev := newEvaluator(m.code)
+
+	xs, err := ev.evalSeq(m.code.Seq, []any{input})
+	if err != nil {
+		return nil, err
+	}
+
+	seq := make([]B, len(xs))
+	for i, x := range xs {
+		v, ok := x.(B)
+		// Note: nil value of interface type B is not assertable, it is zero value
+		if !ok && x != nil {
+			return nil, &TypeError{Type: TypeOf[B](), Value: x}
+		}
+		seq[i] = v
+	}
+
+	return seq, nil
+}
+
+// TypeError is the failure of evaluation, the morphism produces value, which
+// is not of the declared category.
+type TypeError struct {
+	// Declared category of morphism
+	Type string
+	// Produced value
+	Value any
+}
+
+func (err *TypeError) Error() string {
+	return fmt.Sprintf("morphism ⟼ %s produces value of type %T", err.Type, err.Value)
+}
+
+// evaluator of morphism with its failure policy
+type evaluator struct {
+	deadLetter func(Failure) error
+}
+
+func newEvaluator(node *AstSeq) *evaluator {
+	ev := &evaluator{}
+	if node.DeadLetter == nil {
+		return ev
+	}
+
+	f := reflect.ValueOf(node.DeadLetter)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 1 || f.Type().NumOut() != 1 || f.Type().Out(0) != typeError {
+		// Note: dead letter targets other than func(Failure) error are not evaluated
+		return ev
+	}
+
+	ev.deadLetter = func(x Failure) error {
+		out := f.Call([]reflect.Value{reflect.ValueOf(x)})
+		if !out[0].IsNil() {
+			return out[0].Interface().(error)
+		}
+		return nil
+	}
+	return ev
+}
+
+func (ev *evaluator) evalSeq(seq []Ast, xs []any) ([]any, error) {
+	var err error
+
+	for _, node := range seq {
+		switch n := node.(type) {
+		case *AstFrom:
+			// the input value is fed instead of the source
+		case *AstMap:
+			if xs, err = ev.evalMap(n, xs); err != nil {
+				return nil, err
+			}
+		case *AstYield:
+			if err = evalYield(n, xs); err != nil {
+				return nil, err
+			}
+			xs = nil
+		case *AstSeq:
+			if xs, err = ev.evalNested(n, xs); err != nil {
+				return nil, err
+			}
+		case *AstFork:
+			if _, err = ev.evalBranches(n.Branches, xs); err != nil {
+				return nil, err
+			}
+			xs = nil
+		case *AstMerge:
+			if xs, err = ev.evalBranches(n.Branches, xs); err != nil {
+				return nil, err
+			}
+		case *AstZip:
+			if xs, err = ev.evalZip(n, xs); err != nil {
+				return nil, err
+			}
+		case *AstFilter:
+			if xs, err = evalFilter(n, xs); err != nil {
+				return nil, err
+			}
+		case *AstSwitch:
+			if xs, err = ev.evalSwitch(n, xs); err != nil {
+				return nil, err
+			}
+		case *AstFold:
+			if xs, err = evalFold(n, xs); err != nil {
+				return nil, err
+			}
+		case *AstBatch:
+			if xs, err = evalBatch(n, xs); err != nil {
+				return nil, err
+			}
+		case *AstGroupBy:
+			if xs, err = evalGroupBy(n, xs); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported node %T", node)
+		}
+	}
+
+	return xs, nil
+}
+
+var typeError = fn.ErrorType
+
+func (ev *evaluator) evalMap(node *AstMap, xs []any) ([]any, error) {
+	f, err := transformer(node)
+	if err != nil {
+		return nil, err
+	}
+
+	ys := make([]any, 0, len(xs))
+	for _, x := range xs {
+		y, err := f(x)
+		if err == nil {
+			ys = append(ys, y)
+			continue
+		}
+
+		if ev.deadLetter == nil {
+			return nil, err
+		}
+
+		if err := ev.deadLetter(FailureOf(x, err)); err != nil {
+			return nil, err
+		}
+	}
+
+	return ys, nil
+}
+
+// transformer func(A) (B, error) with failure policy of the node
+func transformer(node *AstMap) (func(any) (any, error), error) {
+	policy := fn.Policy{Catch: node.Catch}
+	if node.Retry != nil {
+		policy.Attempts, policy.Backoff = node.Retry.Attempts, node.Retry.Backoff
+	}
+
+	f, _, err := fn.Transformer(context.Background(), node.TypeA, node.TypeB, node.F, policy)
+	return f, err
+}
+
+func evalYield(node *AstYield, xs []any) error {
+	if node.Target == nil {
+		return nil
+	}
+
+	f := reflect.ValueOf(node.Target)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 1 || f.Type().NumOut() != 1 || f.Type().Out(0) != typeError {
+		// Note: targets other than func(B) error are not evaluated
+		return nil
+	}
+
+	in := f.Type().In(0)
+	for _, x := range xs {
+		out := f.Call([]reflect.Value{fn.ValueOf(x, in)})
+		if !out[0].IsNil() {
+			return out[0].Interface().(error)
+		}
+	}
+
+	return nil
+}
+
+func (ev *evaluator) evalNested(node *AstSeq, xs []any) ([]any, error) {
+	// open context, elements are flattened
+	if node.Deferred {
+		flat := make([]any, 0)
+		for _, x := range xs {
+			seq, err := fn.Elements(x)
+			if err != nil {
+				return nil, err
+			}
+			flat = append(flat, seq...)
+		}
+		return ev.evalSeq(node.Seq, flat)
+	}
+
+	// closed context, elements are united back to slice
+	ys := make([]any, len(xs))
+	for i, x := range xs {
+		seq, err := fn.Elements(x)
+		if err != nil {
+			return nil, err
+		}
+
+		vs, err := ev.evalSeq(node.Seq, seq)
+		if err != nil {
+			return nil, err
+		}
+
+		t := typeOfSeq(node.Seq, reflect.TypeOf(x).Elem())
+		eg := reflect.MakeSlice(reflect.SliceOf(t), len(vs), len(vs))
+		for k, v := range vs {
+			eg.Index(k).Set(fn.ValueOf(v, t))
+		}
+		ys[i] = eg.Interface()
+	}
+
+	return ys, nil
+}
+
+// each branch is evaluated for all elements, results of branches are merged
+func (ev *evaluator) evalBranches(branches []*AstSeq, xs []any) ([]any, error) {
+	ys := make([]any, 0)
+	for _, b := range branches {
+		vs, err := ev.evalSeq(b.Seq, xs)
+		if err != nil {
+			return nil, err
+		}
+		ys = append(ys, vs...)
+	}
+
+	return ys, nil
+}
+
+// each element is evaluated by both branches, results are united into pair
+func (ev *evaluator) evalZip(node *AstZip, xs []any) ([]any, error) {
+	if len(node.Branches) != 2 || node.Pair == nil {
+		return nil, fmt.Errorf("zip ⟼ %s requires two branches", node.TypeB)
+	}
+
+	ys := make([]any, len(xs))
+	for i, x := range xs {
+		pair := reflect.New(node.Pair).Elem()
+		for k, b := range node.Branches {
+			vs, err := ev.evalSeq(b.Seq, []any{x})
+			if err != nil {
+				return nil, err
+			}
+			if len(vs) != 1 {
+				return nil, fmt.Errorf("zip ⟼ %s requires single value from branch %d, got %d", node.TypeB, k, len(vs))
+			}
+			pair.Field(k).Set(fn.ValueOf(vs[0], pair.Field(k).Type()))
+		}
+		ys[i] = pair.Interface()
+	}
+
+	return ys, nil
+}
+
+func evalFilter(node *AstFilter, xs []any) ([]any, error) {
+	f, err := fn.Predicate(node.Type, node.F)
+	if err != nil {
+		return nil, err
+	}
+
+	ys := make([]any, 0, len(xs))
+	for _, x := range xs {
+		ok, err := f(x)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ys = append(ys, x)
+		}
+	}
+
+	return ys, nil
+}
+
+// each element is evaluated by the branch of first matching case
+func (ev *evaluator) evalSwitch(node *AstSwitch, xs []any) ([]any, error) {
+	preds := make([]func(any) (bool, error), len(node.Cases))
+	for i, c := range node.Cases {
+		if c.F != nil {
+			f, err := fn.Predicate(c.Type, c.F)
+			if err != nil {
+				return nil, err
+			}
+			preds[i] = f
+		}
+	}
+
+	ys := make([]any, 0, len(xs))
+	for _, x := range xs {
+		for i, c := range node.Cases {
+			if !node.Match(c, x) {
+				continue
+			}
+
+			if preds[i] != nil {
+				ok, err := preds[i](x)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+
+			vs, err := ev.evalSeq(c.Branch.Seq, []any{x})
+			if err != nil {
+				return nil, err
+			}
+			ys = append(ys, vs...)
+			break
+		}
+	}
+
+	return ys, nil
+}
+
+// all elements are combined by the monoid into the single value
+func evalFold(node *AstFold, xs []any) ([]any, error) {
+	empty, combine, _, err := fn.Monoid(node.Type, node.Monoid)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := empty()
+	for _, x := range xs {
+		acc = combine(acc, x)
+	}
+
+	return []any{acc}, nil
+}
+
+// elements are windowed into batches of the size, the last one might be incomplete
+func evalBatch(node *AstBatch, xs []any) ([]any, error) {
+	if node.Size < 1 || node.Slice == nil {
+		return nil, fmt.Errorf("batch of %s requires positive size", node.Type)
+	}
+
+	ys := make([]any, 0, (len(xs)+node.Size-1)/node.Size)
+	for i := 0; i < len(xs); i += node.Size {
+		ys = append(ys, fn.Batch(node.Slice, xs[i:min(i+node.Size, len(xs))]))
+	}
+
+	return ys, nil
+}
+
+// elements are grouped by key into the single value
+func evalGroupBy(node *AstGroupBy, xs []any) ([]any, error) {
+	key, t, err := fn.Grouping(node.TypeA, node.F)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := reflect.MakeMap(t)
+	for _, x := range xs {
+		if err := fn.Group(groups, key, x); err != nil {
+			return nil, err
+		}
+	}
+
+	return []any{groups.Interface()}, nil
+}
+
+// type of values produced by the sequence for the input of type t
+func typeOfSeq(seq []Ast, t reflect.Type) reflect.Type {
+	for _, node := range seq {
+		switch n := node.(type) {
+		case *AstMap:
+			if f := reflect.TypeOf(n.F); f != nil && f.Kind() == reflect.Func && f.NumOut() > 0 {
+				t = f.Out(0)
+			}
+		case *AstYield, *AstFork:
+			t = reflect.TypeOf(new(Void)).Elem()
+		case *AstMerge:
+			if len(n.Branches) > 0 {
+				t = typeOfSeq(n.Branches[0].Seq, t)
+			}
+		case *AstZip:
+			if n.Pair != nil {
+				t = n.Pair
+			}
+		case *AstSwitch:
+			if len(n.Cases) > 0 {
+				t = typeOfSeq(n.Cases[0].Branch.Seq, t)
+			}
+		case *AstBatch:
+			if n.Slice != nil {
+				t = n.Slice
+			}
+		case *AstGroupBy:
+			if f := reflect.TypeOf(n.F); f != nil && f.Kind() == reflect.Func && f.NumIn() == 1 && f.NumOut() > 0 && f.Out(0).Comparable() {
+				t = reflect.MapOf(f.Out(0), reflect.SliceOf(f.In(0)))
+			}
+		case *AstSeq:
+			if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+				return t
+			}
+			if n.Deferred {
+				t = typeOfSeq(n.Seq, t.Elem())
+			} else {
+				t = reflect.SliceOf(typeOfSeq(n.Seq, t.Elem()))
+			}
+		}
+	}
+	return t
+}
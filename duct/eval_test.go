@@ -0,0 +1,401 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/it/v2"
+)
+
+var (
+	itoa = duct.L2[int, string](func(x int) (string, error) { return strconv.Itoa(x), nil })
+	atoi = duct.L2[string, int](strconv.Atoi)
+	dup  = duct.L2[int, []int](func(x int) ([]int, error) {
+		seq := make([]int, x)
+		for i := 0; i < x; i++ {
+			seq[i] = x
+		}
+		return seq, nil
+	})
+	src  = duct.L1[int](nil)
+	odd  = duct.L2[int, bool](func(x int) (bool, error) { return x%2 == 1, nil })
+	upto = duct.L2[int, []int](func(x int) ([]int, error) {
+		seq := make([]int, x)
+		for i := 0; i < x; i++ {
+			seq[i] = i + 1
+		}
+		return seq, nil
+	})
+)
+
+type event any
+
+// monoid of integers under addition
+type sum struct{}
+
+func (sum) Empty() int           { return 0 }
+func (sum) Combine(a, b int) int { return a + b }
+
+func TestEval(t *testing.T) {
+	t.Run("From", func(t *testing.T) {
+		v, err := duct.Eval(duct.From(src), 1)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, 1),
+		)
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		v, err := duct.Eval(duct.Join(atoi, duct.Join(itoa, duct.From(src))), 10)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, 10),
+		)
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		fail := duct.L2[int, int](func(int) (int, error) { return 0, fmt.Errorf("fail") })
+		_, err := duct.Eval(duct.Join(fail, duct.From(src)), 10)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("fail"),
+		)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := duct.Eval(duct.Join(duct.L2[int, string](strconv.Itoa), duct.From(src)), 10)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("is not func(int) (string, error)"),
+		)
+	})
+
+	t.Run("InvalidType", func(t *testing.T) {
+		f := duct.L2[int, string](func(x int) (int, error) { return x, nil })
+		_, err := duct.Eval(duct.Join(f, duct.From(src)), 10)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Fail(func() error { return err }).Contain("transformer output int does not match string"),
+		)
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		f := duct.L2[string, int](func(x int) (int, error) { return x, nil })
+		_, err := duct.Eval(duct.Join(f, duct.From(duct.L1[string](nil))), "1")
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Fail(func() error { return err }).Contain("transformer input int does not match string"),
+		)
+	})
+
+	t.Run("Unit", func(t *testing.T) {
+		v, err := duct.Eval(duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(src)))), 3)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal("3", "3", "3"),
+		)
+	})
+
+	t.Run("UnitWrapF", func(t *testing.T) {
+		v, err := duct.Eval(duct.Unit(duct.WrapF(duct.Join(dup, duct.From(src)))), 2)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal(2, 2),
+		)
+	})
+
+	t.Run("UnitNested", func(t *testing.T) {
+		m := duct.Unit(
+			duct.LiftF(dup,
+				duct.Join(dup, duct.From(src)),
+			),
+		)
+		v, err := duct.Eval(m, 2)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equiv(v, [][]int{{2, 2}, {2, 2}}),
+		)
+	})
+
+	t.Run("WrapF", func(t *testing.T) {
+		m := duct.WrapF(duct.Join(dup, duct.From(src)))
+
+		_, err := duct.Eval(m, 2)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+
+		seq, err := duct.EvalSeq(m, 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(2, 2),
+		)
+	})
+
+	t.Run("Yield", func(t *testing.T) {
+		seq := make([]string, 0)
+		eg := duct.L1[string](func(x string) error {
+			seq = append(seq, x)
+			return nil
+		})
+
+		m := duct.Yield(eg, duct.LiftF(itoa, duct.Join(dup, duct.From(src))))
+		_, err := duct.Eval(m, 2)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("2", "2"),
+		)
+	})
+
+	t.Run("Fork", func(t *testing.T) {
+		seqA, seqB := make([]int, 0), make([]string, 0)
+		egA := duct.L1[int](func(x int) error { seqA = append(seqA, x); return nil })
+		egB := duct.L1[string](func(x string) error { seqB = append(seqB, x); return nil })
+
+		m := duct.Fork(duct.From(src),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, duct.Void] { return duct.Yield(egA, m) },
+			func(m duct.Morphism[int, int]) duct.Morphism[int, duct.Void] {
+				return duct.Yield(egB, duct.Join(itoa, m))
+			},
+		)
+		_, err := duct.Eval(m, 2)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seqA).Equal(2),
+			it.Seq(seqB).Equal("2"),
+		)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		m := duct.Merge(duct.From(src),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) },
+			func(m duct.Morphism[int, int]) duct.Morphism[int, string] {
+				return duct.LiftF(itoa, duct.Join(dup, m))
+			},
+		)
+		seq, err := duct.EvalSeq(m, 2)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("2", "2", "2"),
+		)
+	})
+
+	t.Run("Zip", func(t *testing.T) {
+		m := duct.Zip(duct.From(src),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) },
+			func(m duct.Morphism[int, int]) duct.Morphism[int, []int] { return duct.Join(dup, m) },
+		)
+		v, err := duct.Eval(m, 2)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v.Fst, "2"),
+			it.Seq(v.Snd).Equal(2, 2),
+		)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		m := duct.Unit(duct.Filter(odd, duct.WrapF(duct.Join(dup, duct.From(src)))))
+
+		v, err := duct.Eval(m, 3)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal(3, 3, 3),
+		)
+
+		v, err = duct.Eval(m, 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(v), 0),
+		)
+	})
+
+	t.Run("SwitchWhen", func(t *testing.T) {
+		m := duct.Switch(duct.From(src),
+			duct.When(odd, func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) }),
+			duct.Otherwise(func(m duct.Morphism[int, int]) duct.Morphism[int, string] {
+				return duct.LiftF(itoa, duct.Join(dup, m))
+			}),
+		)
+
+		seq, err := duct.EvalSeq(m, 1)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("1"),
+		)
+
+		seq, err = duct.EvalSeq(m, 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("2", "2"),
+		)
+	})
+
+	t.Run("SwitchIs", func(t *testing.T) {
+		m := duct.Switch(duct.From(duct.L1[event](nil)),
+			duct.Is[event, int](func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) }),
+			duct.Is[event, string](func(m duct.Morphism[string, string]) duct.Morphism[string, string] { return m }),
+		)
+
+		v, err := duct.Eval(m, event(10))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, "10"),
+		)
+
+		v, err = duct.Eval(m, event("x"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, "x"),
+		)
+
+		seq, err := duct.EvalSeq(m, event(1.0))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 0),
+		)
+	})
+	t.Run("Compose", func(t *testing.T) {
+		fragment := duct.Join(atoi, duct.Join(itoa, duct.Fragment[int]()))
+
+		v, err := duct.Eval(duct.Compose(duct.From(src), fragment), 10)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, 10),
+		)
+
+		seq, err := duct.Eval(duct.Unit(duct.Compose(duct.WrapF(duct.Join(dup, duct.From(src))), fragment)), 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(2, 2),
+		)
+
+		v, err = duct.Eval(fragment, 10)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, 10),
+		)
+	})
+
+	t.Run("ComposeUnit", func(t *testing.T) {
+		fragment := duct.Unit(duct.Join(itoa, duct.Fragment[int]()))
+
+		v, err := duct.Eval(duct.Compose(duct.From(src), fragment), 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal("2"),
+		)
+
+		seq, err := duct.EvalSeq(duct.Compose(duct.WrapF(duct.Join(dup, duct.From(src))), fragment), 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 2),
+			it.Seq(seq[0]).Equal("2"),
+			it.Seq(seq[1]).Equal("2"),
+		)
+
+		head := duct.L2[[]string, string](func(x []string) (string, error) { return x[0], nil })
+		s, err := duct.Eval(duct.Join(head, fragment), 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(s, "2"),
+		)
+	})
+
+	t.Run("UnitRoot", func(t *testing.T) {
+		m := duct.Unit(duct.Join(itoa, duct.From(src)))
+
+		v, err := duct.Eval(m, 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal("2"),
+		)
+	})
+
+	t.Run("ComposeReuse", func(t *testing.T) {
+		fragment := duct.WrapF(duct.Join(dup, duct.Fragment[int]()))
+
+		a := duct.Unit(duct.Join(itoa, duct.Compose(duct.From(src), fragment)))
+		b := duct.Unit(duct.Compose(duct.From(src), fragment))
+
+		va, err := duct.Eval(a, 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(va).Equal("2", "2"),
+		)
+
+		vb, err := duct.Eval(b, 2)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(vb).Equal(2, 2),
+		)
+	})
+
+	t.Run("Fold", func(t *testing.T) {
+		v, err := duct.Eval(duct.Unit(duct.Fold(sum{}, duct.WrapF(duct.Join(upto, duct.From(src))))), 4)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal(10),
+		)
+
+		v, err = duct.Eval(duct.Unit(duct.Fold(sum{}, duct.WrapF(duct.Join(upto, duct.From(src))))), 0)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal(0),
+		)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		v, err := duct.Eval(duct.Unit(duct.Batch(2, duct.WrapF(duct.Join(upto, duct.From(src))))), 5)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(v), 3),
+			it.Seq(v[0]).Equal(1, 2),
+			it.Seq(v[1]).Equal(3, 4),
+			it.Seq(v[2]).Equal(5),
+		)
+	})
+
+	t.Run("GroupBy", func(t *testing.T) {
+		v, err := duct.Eval(duct.Unit(duct.GroupBy(odd, duct.WrapF(duct.Join(upto, duct.From(src))))), 5)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(v), 1),
+			it.Seq(v[0][true]).Equal(1, 3, 5),
+			it.Seq(v[0][false]).Equal(2, 4),
+		)
+	})
+
+	t.Run("ComposeSource", func(t *testing.T) {
+		defer func() {
+			it.Then(t).ShouldNot(
+				it.Nil(recover()),
+			)
+		}()
+
+		duct.Compose(duct.From(src), duct.From(src))
+	})
+}
@@ -0,0 +1,599 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+// Package gen is the code generator of duct morphisms. It walks the morphism
+// and emits standalone Go file, which wires transformers with pipe (or fork)
+// combinators. The generated code is type safe, it does not use reflection.
+//
+// Values of morphism (sources, transformers, predicates and targets) are
+// referenced by symbols of the registry. Symbols are Go identifiers of
+// package level functions and variables declared in the package of
+// the generated file:
+//
+//	r := duct.NewRegistry()
+//	r.Register("Itoa", duct.L2[int, string](Itoa))
+//
+// The generator follows the reference interpreter duct/stream:
+//   - AstFrom source is a channel, slice or array variable;
+//   - AstMap transformer is a function func(A) (B, error), executed by
+//     pipe.Map or fork.Map if parallelism attribute is defined, the retry
+//     policy and catch handler are generated inline;
+//   - AstSeq, built by LiftF or WrapF, flattens the elements of []A;
+//   - AstSeq, closed by Unit, is an element-wise function over []A;
+//   - AstYield target is a channel or a function func(B) error;
+//   - AstFilter predicate is a function func(A) (bool, error);
+//   - AstFold monoid is a variable of monoid.Monoid[A], executed by pipe.Fold
+//     over the stream.
+//
+// Branches (Fork, Merge, Zip, Switch), aggregations other than Fold and dead
+// letter are not supported yet.
+package gen
+
+import (
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fogfish/golem/duct"
+)
+
+// Config of generated file
+type Config struct {
+	// Name of generated package
+	Package string
+	// Import path of generated package, its types are referred unqualified
+	PkgPath string
+	// Name of generated function
+	Func string
+}
+
+// Generate Go source code of morphism 𝑚: A ⟼ B. The generated function
+// runs the pipeline, it returns the channel of results and the channel of
+// errors, failed elements are dropped from the pipeline:
+//
+//	func Name(ctx context.Context) (<-chan B, <-chan error)
+//
+// The source-less morphism (fragment) consumes the input channel:
+//
+//	func Name(ctx context.Context, in <-chan A) (<-chan B, <-chan error)
+//
+// Targets consume elements, the morphism terminated by target produces
+// the channel of struct{}, which is closed when all elements are consumed.
+func Generate[A, B any](m duct.Morphism[A, B], r *duct.Registry, c Config) ([]byte, error) {
+	g := New(r, c, reflect.TypeFor[A]())
+	if err := m.Apply(g); err != nil {
+		return nil, err
+	}
+
+	return g.Source()
+}
+
+// Generator is the visitor that generates code of morphism.
+type Generator struct {
+	duct.AstVisitor
+	config   Config
+	registry *duct.Registry
+	imports  map[string]string
+	input    reflect.Type
+	source   bool
+	scope    []*scope
+	body     *strings.Builder
+	exx      []string
+	out      *scope
+	id       int
+}
+
+var _ duct.Visitor = (*Generator)(nil)
+
+// code generation scope, either streaming or element-wise synchronous
+type scope struct {
+	stream bool
+	// channel or variable of current value
+	v string
+	// type of current value, nil if value is ø
+	t reflect.Type
+	// element-wise function over the slice of type in
+	in   reflect.Type
+	body *strings.Builder
+}
+
+// New creates the generator of morphism, the type of morphism input is
+// required by fragments.
+func New(r *duct.Registry, c Config, input reflect.Type) *Generator {
+	return &Generator{
+		config:   c,
+		registry: r,
+		imports: map[string]string{
+			"context":                          "context",
+			"github.com/fogfish/golem/pipe/v2": "pipe",
+		},
+		input: input,
+		body:  &strings.Builder{},
+	}
+}
+
+func (g *Generator) top() *scope { return g.scope[len(g.scope)-1] }
+
+// unique identity of variables of the stage
+func (g *Generator) vid() int {
+	g.id++
+	return g.id
+}
+
+// Source code of generated file
+func (g *Generator) Source() ([]byte, error) {
+	if g.out == nil {
+		return nil, fmt.Errorf("morphism is not generated")
+	}
+
+	a, in := "ø", ""
+	if !g.source {
+		t, err := g.typeOf(g.input)
+		if err != nil {
+			return nil, err
+		}
+		a, in = t, ", in <-chan "+t
+	}
+
+	b, t := "ø", "struct{}"
+	if g.out.t != nil {
+		var err error
+		if t, err = g.typeOf(g.out.t); err != nil {
+			return nil, err
+		}
+		b = t
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("// Code generated by duct/gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(sb, "package %s\n\nimport (\n", g.config.Package)
+	for _, group := range g.importGroups() {
+		for _, path := range group {
+			if name := g.imports[path]; name != pathName(path) {
+				fmt.Fprintf(sb, "%s %q\n", name, path)
+			} else {
+				fmt.Fprintf(sb, "%q\n", path)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(")\n\n")
+
+	fmt.Fprintf(sb, "// %s runs the pipeline of morphism 𝑚: %s ⟼ %s\n", g.config.Func, a, b)
+	fmt.Fprintf(sb, "func %s(ctx context.Context%s) (<-chan %s, <-chan error) {\n", g.config.Func, in, t)
+	sb.WriteString(g.body.String())
+	fmt.Fprintf(sb, "\nreturn %s, pipe.Join[error](ctx, %s)\n}\n", g.out.v, strings.Join(g.exx, ", "))
+
+	return format.Source([]byte(sb.String()))
+}
+
+// imports grouped by standard and other packages
+func (g *Generator) importGroups() [][]string {
+	std, ext := make([]string, 0), make([]string, 0)
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			ext = append(ext, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(ext)
+
+	return [][]string{std, ext}
+}
+
+//------------------------------------------------------------------------------
+
+func (g *Generator) OnEnterMorphism(depth int, node duct.AstSeq) error {
+	if len(g.scope) != 0 {
+		return fmt.Errorf("branches are not supported at depth %d", depth)
+	}
+
+	if node.DeadLetter != nil {
+		return fmt.Errorf("dead letter is not supported")
+	}
+
+	g.scope = append(g.scope, &scope{stream: true, v: "in", t: g.input, body: g.body})
+	return nil
+}
+
+func (g *Generator) OnLeaveMorphism(depth int, node duct.AstSeq) error {
+	g.out = g.top()
+	g.scope = g.scope[:len(g.scope)-1]
+	return nil
+}
+
+func (g *Generator) OnEnterSeq(depth int, node duct.AstSeq) error {
+	top := g.top()
+
+	elem, err := elemOf(top.t)
+	if err != nil {
+		return err
+	}
+
+	if node.Deferred {
+		if !top.stream {
+			return fmt.Errorf("deferred sequence is not supported within closed one at depth %d", depth)
+		}
+
+		seq, err := g.typeOf(top.t)
+		if err != nil {
+			return err
+		}
+		et, err := g.typeOf(elem)
+		if err != nil {
+			return err
+		}
+
+		id := g.vid()
+		s, e := fmt.Sprintf("s%d", id), fmt.Sprintf("e%d", id)
+		g.exx = append(g.exx, e)
+		fmt.Fprintf(top.body, "%s, %s := pipe.FMap(ctx, %s, pipe.TryF(func(ctx context.Context, xs %s, out chan<- %s) error {\n", s, e, top.v, seq, et)
+		top.body.WriteString("for _, x := range xs {\nselect {\ncase out <- x:\ncase <-ctx.Done():\nreturn ctx.Err()\n}\n}\nreturn nil\n}))\n")
+
+		g.scope = append(g.scope, &scope{stream: true, v: s, t: elem, body: top.body})
+		return nil
+	}
+
+	g.scope = append(g.scope, &scope{stream: false, v: "x", t: elem, in: top.t, body: &strings.Builder{}})
+	return nil
+}
+
+func (g *Generator) OnLeaveSeq(depth int, node duct.AstSeq) error {
+	inner := g.top()
+	g.scope = g.scope[:len(g.scope)-1]
+	top := g.top()
+
+	if inner.stream {
+		top.v, top.t = inner.v, inner.t
+		return nil
+	}
+
+	// closed sequence is element-wise function over []A
+	et := reflect.TypeFor[struct{}]()
+	if inner.t != nil {
+		et = inner.t
+	}
+
+	in, err := g.typeOf(inner.in)
+	if err != nil {
+		return err
+	}
+	out, err := g.typeOf(reflect.SliceOf(et))
+	if err != nil {
+		return err
+	}
+
+	v := inner.v
+	if inner.t == nil {
+		v = "struct{}{}"
+	}
+
+	f := fmt.Sprintf("func(xs %s) (%s, error) {\nys := make(%s, 0, len(xs))\nfor _, x := range xs {\n%sys = append(ys, %s)\n}\nreturn ys, nil\n}",
+		in, out, out, inner.body.String(), v,
+	)
+
+	g.apply(top, f, reflect.SliceOf(et), 0)
+	return nil
+}
+
+func (g *Generator) OnEnterMap(depth int, node duct.AstMap) error {
+	top := g.top()
+
+	f, t, err := g.function(top, node)
+	if err != nil {
+		return err
+	}
+
+	g.apply(top, f, t, node.Attrs.Parallelism)
+	return nil
+}
+
+func (g *Generator) OnEnterFrom(depth int, node duct.AstFrom) error {
+	top := g.top()
+
+	name, err := g.symbol(node.Source)
+	if err != nil {
+		return err
+	}
+
+	st := reflect.TypeOf(node.Source)
+	elem, err := elemOf(st)
+	if err != nil {
+		return err
+	}
+
+	et, err := g.typeOf(elem)
+	if err != nil {
+		return err
+	}
+
+	s := fmt.Sprintf("s%d", g.vid())
+	switch st.Kind() {
+	case reflect.Chan:
+		fmt.Fprintf(top.body, "var %s <-chan %s = %s\n", s, et, name)
+	case reflect.Array:
+		fmt.Fprintf(top.body, "%s := pipe.Seq(%s[:]...)\n", s, name)
+	default:
+		fmt.Fprintf(top.body, "%s := pipe.Seq(%s...)\n", s, name)
+	}
+
+	top.v, top.t = s, elem
+	g.source = true
+	return nil
+}
+
+func (g *Generator) OnEnterYield(depth int, node duct.AstYield) error {
+	top := g.top()
+
+	name, err := g.symbol(node.Target)
+	if err != nil {
+		return err
+	}
+
+	var call string
+	tt := reflect.TypeOf(node.Target)
+	switch {
+	case tt.Kind() == reflect.Chan && tt.ChanDir()&reflect.SendDir != 0:
+		if top.stream {
+			call = fmt.Sprintf("select {\ncase %s <- x:\nreturn nil\ncase <-ctx.Done():\nreturn ctx.Err()\n}\n", name)
+		} else {
+			call = fmt.Sprintf("select {\ncase %s <- %s:\ncase <-ctx.Done():\nreturn nil, ctx.Err()\n}\n", name, top.v)
+		}
+	case tt.Kind() == reflect.Func && tt.NumIn() == 1 && tt.NumOut() == 1 && tt.Out(0) == typeError:
+		if top.stream {
+			call = fmt.Sprintf("return %s(x)\n", name)
+		} else {
+			call = fmt.Sprintf("if err := %s(%s); err != nil {\nreturn nil, err\n}\n", name, top.v)
+		}
+	default:
+		return fmt.Errorf("target of %s is not channel or func(%s) error: %T", node.Type, node.Type, node.Target)
+	}
+
+	if !top.stream {
+		top.body.WriteString(call)
+		top.t = nil
+		return nil
+	}
+
+	t, err := g.typeOf(top.t)
+	if err != nil {
+		return err
+	}
+
+	id := g.vid()
+	s, e := fmt.Sprintf("s%d", id), fmt.Sprintf("e%d", id)
+	g.exx = append(g.exx, e)
+	fmt.Fprintf(top.body, "%s, %s := pipe.FMap(ctx, %s, pipe.TryF(func(ctx context.Context, x %s, out chan<- struct{}) error {\n%s}))\n", s, e, top.v, t, call)
+
+	top.v, top.t = s, nil
+	return nil
+}
+
+func (g *Generator) OnEnterFilter(depth int, node duct.AstFilter) error {
+	top := g.top()
+
+	name, err := g.symbol(node.F)
+	if err != nil {
+		return err
+	}
+
+	ft := reflect.TypeOf(node.F)
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 2 || ft.Out(0).Kind() != reflect.Bool || ft.Out(1) != typeError {
+		return fmt.Errorf("predicate of %s is not func(A) (bool, error): %T", node.Type, node.F)
+	}
+
+	if !top.stream {
+		fmt.Fprintf(top.body, "if ok, err := %s(%s); err != nil {\nreturn nil, err\n} else if !ok {\ncontinue\n}\n", name, top.v)
+		return nil
+	}
+
+	f, err := g.adapt(top.t, name, ft)
+	if err != nil {
+		return err
+	}
+
+	id := g.vid()
+	s, e := fmt.Sprintf("s%d", id), fmt.Sprintf("e%d", id)
+	g.exx = append(g.exx, e)
+	fmt.Fprintf(top.body, "%s, %s := pipe.FilterE(ctx, %s, pipe.Try(%s))\n", s, e, top.v, f)
+
+	top.v = s
+	return nil
+}
+
+func (g *Generator) OnEnterFork(depth int, node duct.AstFork) error {
+	return fmt.Errorf("fork is not supported at depth %d", depth)
+}
+
+func (g *Generator) OnEnterMerge(depth int, node duct.AstMerge) error {
+	return fmt.Errorf("merge is not supported at depth %d", depth)
+}
+
+func (g *Generator) OnEnterZip(depth int, node duct.AstZip) error {
+	return fmt.Errorf("zip is not supported at depth %d", depth)
+}
+
+func (g *Generator) OnEnterSwitch(depth int, node duct.AstSwitch) error {
+	return fmt.Errorf("switch is not supported at depth %d", depth)
+}
+
+func (g *Generator) OnEnterFold(depth int, node duct.AstFold) error {
+	top := g.top()
+	if !top.stream {
+		return fmt.Errorf("fold is not supported within closed sequence at depth %d", depth)
+	}
+
+	name, err := g.symbol(node.Monoid)
+	if err != nil {
+		return err
+	}
+
+	s := fmt.Sprintf("s%d", g.vid())
+	fmt.Fprintf(top.body, "%s := pipe.Fold(ctx, %s, %s)\n", s, top.v, name)
+
+	top.v = s
+	return nil
+}
+
+func (g *Generator) OnEnterBatch(depth int, node duct.AstBatch) error {
+	return fmt.Errorf("batch is not supported at depth %d", depth)
+}
+
+func (g *Generator) OnEnterGroupBy(depth int, node duct.AstGroupBy) error {
+	return fmt.Errorf("group by is not supported at depth %d", depth)
+}
+
+//------------------------------------------------------------------------------
+
+// applies function f: A ⟼ B to the current value of scope
+func (g *Generator) apply(top *scope, f string, t reflect.Type, par int) {
+	if !top.stream {
+		v := fmt.Sprintf("v%d", g.vid())
+		fmt.Fprintf(top.body, "%s, err := %s(%s)\nif err != nil {\nreturn nil, err\n}\n", v, f, top.v)
+		top.v, top.t = v, t
+		return
+	}
+
+	id := g.vid()
+	s, e := fmt.Sprintf("s%d", id), fmt.Sprintf("e%d", id)
+	g.exx = append(g.exx, e)
+	if par > 1 {
+		g.imports["github.com/fogfish/golem/pipe/v2/fork"] = "fork"
+		fmt.Fprintf(top.body, "%s, %s := fork.Map(ctx, %d, %s, fork.Try(%s))\n", s, e, par, top.v, f)
+	} else {
+		fmt.Fprintf(top.body, "%s, %s := pipe.Map(ctx, %s, pipe.Try(%s))\n", s, e, top.v, f)
+	}
+	top.v, top.t = s, t
+}
+
+var typeError = reflect.TypeFor[error]()
+
+// function of transformer func(A) (B, error) with failure policy of the node
+func (g *Generator) function(top *scope, node duct.AstMap) (string, reflect.Type, error) {
+	name, err := g.symbol(node.F)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ft := reflect.TypeOf(node.F)
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 2 || ft.Out(1) != typeError {
+		return "", nil, fmt.Errorf("transformer %s ⟼ %s is not func(A) (B, error): %T", node.TypeA, node.TypeB, node.F)
+	}
+
+	if node.Retry == nil && node.Catch == nil {
+		if !top.stream {
+			return name, ft.Out(0), nil
+		}
+
+		f, err := g.adapt(top.t, name, ft)
+		return f, ft.Out(0), err
+	}
+
+	in, err := g.typeOf(top.t)
+	if err != nil {
+		return "", nil, err
+	}
+	out, err := g.typeOf(ft.Out(0))
+	if err != nil {
+		return "", nil, err
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "func(x %s) (%s, error) {\ny, err := %s(x)\n", in, out, name)
+
+	if node.Retry != nil && node.Retry.Attempts > 1 {
+		g.imports["time"] = "time"
+
+		delays := make([]string, 0, node.Retry.Attempts-1)
+		for i := 2; i <= node.Retry.Attempts; i++ {
+			delays = append(delays, durationOf(node.Retry.Backoff(i)))
+		}
+
+		fmt.Fprintf(sb, "for _, d := range []time.Duration{%s} {\nif err == nil {\nbreak\n}\nselect {\ncase <-time.After(d):\ncase <-ctx.Done():\nreturn *new(%s), ctx.Err()\n}\ny, err = %s(x)\n}\n", strings.Join(delays, ", "), out, name)
+	}
+
+	if node.Catch != nil {
+		catch, err := g.symbol(node.Catch)
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(sb, "if err != nil {\nreturn %s(err)\n}\n", catch)
+	}
+
+	sb.WriteString("return y, err\n}")
+
+	return sb.String(), ft.Out(0), nil
+}
+
+// literal of duration in the largest unit, e.g. 2 * time.Millisecond
+func durationOf(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+
+	for _, u := range []struct {
+		d    time.Duration
+		unit string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	} {
+		if d%u.d == 0 {
+			return fmt.Sprintf("%d * %s", d/u.d, u.unit)
+		}
+	}
+
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
+// adapts function to the type of stream elements, if they differ
+func (g *Generator) adapt(t reflect.Type, name string, ft reflect.Type) (string, error) {
+	if ft.In(0) == t {
+		return name, nil
+	}
+
+	in, err := g.typeOf(t)
+	if err != nil {
+		return "", err
+	}
+	out, err := g.typeOf(ft.Out(0))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("func(x %s) (%s, error) { return %s(x) }", in, out, name), nil
+}
+
+func (g *Generator) symbol(x any) (string, error) {
+	name, has := g.registry.Symbol(x)
+	if !has {
+		return "", fmt.Errorf("symbol of %T is not registered", x)
+	}
+	return name, nil
+}
+
+func elemOf(t reflect.Type) (reflect.Type, error) {
+	if t == nil {
+		return nil, fmt.Errorf("type ø is not functor")
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Chan:
+		return t.Elem(), nil
+	default:
+		return nil, fmt.Errorf("type %s is not functor", t)
+	}
+}
@@ -0,0 +1,179 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package gen_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/golem/duct/gen"
+	"github.com/fogfish/golem/duct/gen/internal/example"
+	"github.com/fogfish/it/v2"
+)
+
+var (
+	source  = duct.L1[int](example.Source)
+	sink    = duct.L1[string](example.Put)
+	odd     = duct.L2[int, bool](example.Odd)
+	dup     = duct.L2[int, []int](example.Dup)
+	itoa    = duct.L2[int, string](example.Itoa)
+	toItem  = duct.L2[string, example.Item](example.ToItem)
+	handler = duct.L2[error, example.Item](example.Recover)
+)
+
+type sum struct{}
+
+func (sum) Empty() int           { return 0 }
+func (sum) Combine(a, b int) int { return a + b }
+
+func registry(t *testing.T) *duct.Registry {
+	r := duct.NewRegistry()
+	it.Then(t).Should(
+		it.Nil(r.Register("Source", source)),
+		it.Nil(r.Register("Put", sink)),
+		it.Nil(r.Register("Odd", odd)),
+		it.Nil(r.Register("Dup", dup)),
+		it.Nil(r.Register("Itoa", itoa)),
+		it.Nil(r.Register("ToItem", toItem)),
+		it.Nil(r.Register("Recover", handler)),
+	)
+	return r
+}
+
+func config(name string) gen.Config {
+	return gen.Config{
+		Package: "example",
+		PkgPath: "github.com/fogfish/golem/duct/gen/internal/example",
+		Func:    name,
+	}
+}
+
+// golden files are generated pipelines of example package
+func golden(t *testing.T, file string, code []byte) {
+	expect, err := os.ReadFile(file)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(string(code), string(expect)),
+	)
+}
+
+func TestGenerate(t *testing.T) {
+	r := registry(t)
+
+	t.Run("Items", func(t *testing.T) {
+		m := duct.Unit(
+			duct.Catch(handler,
+				duct.Join(toItem,
+					duct.LiftF(itoa,
+						duct.Join(
+							duct.Retry(duct.RetryPolicy{Attempts: 3, Delay: time.Millisecond, Factor: 2}, dup).With(duct.Attributes{Parallelism: 2}),
+							duct.Filter(odd, duct.From(source)),
+						),
+					),
+				),
+			),
+		)
+
+		code, err := gen.Generate(m, r, config("Items"))
+		it.Then(t).Should(it.Nil(err))
+		golden(t, "internal/example/items.go", code)
+	})
+
+	t.Run("Yield", func(t *testing.T) {
+		m := duct.Yield(sink, duct.LiftF(itoa, duct.Join(dup, duct.From(source))))
+
+		code, err := gen.Generate(m, r, config("Yield"))
+		it.Then(t).Should(it.Nil(err))
+		golden(t, "internal/example/yield.go", code)
+	})
+
+	t.Run("Fragment", func(t *testing.T) {
+		m := duct.Join(toItem, duct.Join(itoa, duct.Fragment[int]()))
+
+		code, err := gen.Generate(m, r, config("Fragment"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.Contains(string(code), "func Fragment(ctx context.Context, in <-chan int) (<-chan Item, <-chan error)")),
+		)
+	})
+
+	t.Run("Fold", func(t *testing.T) {
+		r := registry(t)
+		it.Then(t).Should(it.Nil(r.Register("Sum", sum{})))
+
+		m := duct.Fold(sum{}, duct.From(source))
+
+		code, err := gen.Generate(m, r, config("Fold"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.Contains(string(code), ":= pipe.Fold(ctx, s1, Sum)")),
+		)
+	})
+
+	t.Run("NotRegistered", func(t *testing.T) {
+		m := duct.Join(duct.L2[int, int](func(x int) (int, error) { return x, nil }), duct.From(source))
+
+		_, err := gen.Generate(m, r, config("Func"))
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("is not registered"),
+		)
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		m := duct.Fork(duct.From(source),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, duct.Void] {
+				return duct.Yield(sink, duct.Join(itoa, m))
+			},
+		)
+
+		_, err := gen.Generate(m, r, config("Func"))
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("fork is not supported"),
+		)
+	})
+
+	t.Run("YieldClosed", func(t *testing.T) {
+		out := duct.L1[string](make(chan string))
+		r := registry(t)
+		it.Then(t).Should(it.Nil(r.Register("Out", out)))
+
+		m := duct.Unit(duct.Yield(out, duct.LiftF(itoa, duct.Join(dup, duct.From(source)))))
+
+		code, err := gen.Generate(m, r, config("Func"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.Contains(string(code), "case Out <- v3:\n\t\t\tcase <-ctx.Done():\n\t\t\t\treturn nil, ctx.Err()")),
+		)
+	})
+
+	t.Run("InvalidTarget", func(t *testing.T) {
+		out := duct.L1[string](func(string) {})
+		r := registry(t)
+		it.Then(t).Should(it.Nil(r.Register("Out", out)))
+
+		m := duct.Yield(out, duct.Join(itoa, duct.From(source)))
+
+		_, err := gen.Generate(m, r, config("Func"))
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("is not channel or func(string) error"),
+		)
+	})
+
+	t.Run("BatchNotSupported", func(t *testing.T) {
+		m := duct.Batch(2, duct.From(source))
+
+		_, err := gen.Generate(m, r, config("Func"))
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("batch is not supported"),
+		)
+	})
+}
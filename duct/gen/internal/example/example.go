@@ -0,0 +1,58 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+// Package example declares symbols of morphisms, pipelines of these
+// morphisms are generated by duct/gen (see gen_test.go).
+package example
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Item of example pipeline
+type Item struct{ ID string }
+
+var Source = []int{1, 2, 3}
+
+func Odd(x int) (bool, error) { return x%2 == 1, nil }
+
+func Dup(x int) ([]int, error) {
+	seq := make([]int, x)
+	for i := 0; i < x; i++ {
+		seq[i] = x
+	}
+	return seq, nil
+}
+
+func Itoa(x int) (string, error) { return strconv.Itoa(x), nil }
+
+func ToItem(s string) (Item, error) {
+	if s == "" {
+		return Item{}, fmt.Errorf("empty id")
+	}
+	return Item{ID: s}, nil
+}
+
+func Recover(err error) (Item, error) { return Item{ID: "-"}, nil }
+
+// Sink of example pipeline
+var Sink sink
+
+type sink struct {
+	sync.Mutex
+	Seq []string
+}
+
+func Put(x string) error {
+	Sink.Lock()
+	defer Sink.Unlock()
+	Sink.Seq = append(Sink.Seq, x)
+	return nil
+}
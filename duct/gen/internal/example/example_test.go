@@ -0,0 +1,39 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package example_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fogfish/golem/duct/gen/internal/example"
+	"github.com/fogfish/golem/pipe/v2"
+	"github.com/fogfish/it/v2"
+)
+
+func TestItems(t *testing.T) {
+	out, exx := example.Items(context.Background())
+	seq := pipe.ToSeq(pipe.StdErr(out, exx))
+
+	it.Then(t).Should(
+		it.Seq(seq).Contain().AllOf(
+			[]example.Item{{ID: "1"}},
+			[]example.Item{{ID: "3"}, {ID: "3"}, {ID: "3"}},
+		),
+	)
+}
+
+func TestYield(t *testing.T) {
+	out, exx := example.Yield(context.Background())
+	<-pipe.Void(context.Background(), pipe.StdErr(out, exx))
+
+	it.Then(t).Should(
+		it.Seq(example.Sink.Seq).Contain().AllOf("1", "2", "2", "3", "3", "3"),
+	)
+}
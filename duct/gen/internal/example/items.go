@@ -0,0 +1,55 @@
+// Code generated by duct/gen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+	"time"
+
+	"github.com/fogfish/golem/pipe/v2"
+	"github.com/fogfish/golem/pipe/v2/fork"
+)
+
+// Items runs the pipeline of morphism 𝑚: ø ⟼ []Item
+func Items(ctx context.Context) (<-chan []Item, <-chan error) {
+	s1 := pipe.Seq(Source...)
+	s2, e2 := pipe.FilterE(ctx, s1, pipe.Try(Odd))
+	s3, e3 := fork.Map(ctx, 2, s2, fork.Try(func(x int) ([]int, error) {
+		y, err := Dup(x)
+		for _, d := range []time.Duration{1 * time.Millisecond, 2 * time.Millisecond} {
+			if err == nil {
+				break
+			}
+			select {
+			case <-time.After(d):
+			case <-ctx.Done():
+				return *new([]int), ctx.Err()
+			}
+			y, err = Dup(x)
+		}
+		return y, err
+	}))
+	s6, e6 := pipe.Map(ctx, s3, pipe.Try(func(xs []int) ([]Item, error) {
+		ys := make([]Item, 0, len(xs))
+		for _, x := range xs {
+			v4, err := Itoa(x)
+			if err != nil {
+				return nil, err
+			}
+			v5, err := func(x string) (Item, error) {
+				y, err := ToItem(x)
+				if err != nil {
+					return Recover(err)
+				}
+				return y, err
+			}(v4)
+			if err != nil {
+				return nil, err
+			}
+			ys = append(ys, v5)
+		}
+		return ys, nil
+	}))
+
+	return s6, pipe.Join[error](ctx, e2, e3, e6)
+}
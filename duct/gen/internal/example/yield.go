@@ -0,0 +1,31 @@
+// Code generated by duct/gen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+
+	"github.com/fogfish/golem/pipe/v2"
+)
+
+// Yield runs the pipeline of morphism 𝑚: ø ⟼ ø
+func Yield(ctx context.Context) (<-chan struct{}, <-chan error) {
+	s1 := pipe.Seq(Source...)
+	s2, e2 := pipe.Map(ctx, s1, pipe.Try(Dup))
+	s3, e3 := pipe.FMap(ctx, s2, pipe.TryF(func(ctx context.Context, xs []int, out chan<- int) error {
+		for _, x := range xs {
+			select {
+			case out <- x:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}))
+	s4, e4 := pipe.Map(ctx, s3, pipe.Try(Itoa))
+	s5, e5 := pipe.FMap(ctx, s4, pipe.TryF(func(ctx context.Context, x string, out chan<- struct{}) error {
+		return Put(x)
+	}))
+
+	return s5, pipe.Join[error](ctx, e2, e3, e4, e5)
+}
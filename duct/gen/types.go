@@ -0,0 +1,167 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package gen
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Go syntax of the type, packages of named types are imported
+func (g *Generator) typeOf(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if strings.ContainsRune(t.Name(), '[') {
+			return "", fmt.Errorf("generic type %s is not supported", t)
+		}
+
+		if t.PkgPath() == "" || t.PkgPath() == g.config.PkgPath {
+			return t.Name(), nil
+		}
+
+		return g.importOf(t.PkgPath()) + "." + t.Name(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := g.typeOf(t.Elem())
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := g.typeOf(t.Elem())
+		return "[]" + elem, err
+	case reflect.Array:
+		elem, err := g.typeOf(t.Elem())
+		return fmt.Sprintf("[%d]%s", t.Len(), elem), err
+	case reflect.Chan:
+		elem, err := g.typeOf(t.Elem())
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + elem, err
+		case reflect.SendDir:
+			return "chan<- " + elem, err
+		default:
+			return "chan " + elem, err
+		}
+	case reflect.Map:
+		key, err := g.typeOf(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := g.typeOf(t.Elem())
+		return fmt.Sprintf("map[%s]%s", key, elem), err
+	case reflect.Func:
+		return g.funcOf(t)
+	case reflect.Struct:
+		return g.structOf(t)
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+		return "", fmt.Errorf("anonymous interface %s is not supported", t)
+	default:
+		return "", fmt.Errorf("type %s is not supported", t)
+	}
+}
+
+func (g *Generator) funcOf(t reflect.Type) (string, error) {
+	in := make([]string, t.NumIn())
+	for i := range in {
+		x := t.In(i)
+		prefix := ""
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			x, prefix = x.Elem(), "..."
+		}
+
+		s, err := g.typeOf(x)
+		if err != nil {
+			return "", err
+		}
+		in[i] = prefix + s
+	}
+
+	out := make([]string, t.NumOut())
+	for i := range out {
+		s, err := g.typeOf(t.Out(i))
+		if err != nil {
+			return "", err
+		}
+		out[i] = s
+	}
+
+	switch len(out) {
+	case 0:
+		return fmt.Sprintf("func(%s)", strings.Join(in, ", ")), nil
+	case 1:
+		return fmt.Sprintf("func(%s) %s", strings.Join(in, ", "), out[0]), nil
+	default:
+		return fmt.Sprintf("func(%s) (%s)", strings.Join(in, ", "), strings.Join(out, ", ")), nil
+	}
+}
+
+func (g *Generator) structOf(t reflect.Type) (string, error) {
+	fields := make([]string, t.NumField())
+	for i := range fields {
+		f := t.Field(i)
+		if f.PkgPath != "" && f.PkgPath != g.config.PkgPath {
+			return "", fmt.Errorf("struct with unexported field %s is not supported", f.Name)
+		}
+
+		s, err := g.typeOf(f.Type)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case f.Anonymous:
+			fields[i] = s
+		default:
+			fields[i] = f.Name + " " + s
+		}
+
+		if f.Tag != "" {
+			fields[i] += " " + fmt.Sprintf("%q", f.Tag)
+		}
+	}
+
+	return "struct{" + strings.Join(fields, "; ") + "}", nil
+}
+
+// name of imported package, unique within the file
+func (g *Generator) importOf(pkg string) string {
+	if name, has := g.imports[pkg]; has {
+		return name
+	}
+
+	name := pathName(pkg)
+	for n := 1; g.hasImport(name); n++ {
+		name = fmt.Sprintf("%s%d", pathName(pkg), n)
+	}
+
+	g.imports[pkg] = name
+	return name
+}
+
+func (g *Generator) hasImport(name string) bool {
+	for _, x := range g.imports {
+		if x == name {
+			return true
+		}
+	}
+	return false
+}
+
+// default name of package, the major version suffix is omitted
+func pathName(pkg string) string {
+	name := path.Base(pkg)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(pkg))
+	}
+	return name
+}
@@ -1,5 +1,13 @@
 module github.com/fogfish/golem/duct
 
-go 1.22
+go 1.24
 
-require github.com/fogfish/it/v2 v2.2.1
+require (
+	github.com/fogfish/golem/pipe/v2 v2.2.0
+	github.com/fogfish/golem/pure v0.10.1
+	github.com/fogfish/it/v2 v2.2.1
+)
+
+require gopkg.in/yaml.v3 v3.0.1
+
+replace github.com/fogfish/golem/pipe/v2 => ../pipe
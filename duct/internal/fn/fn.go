@@ -0,0 +1,226 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+// Package fn implements reflective calls of functions attached to nodes of
+// morphism (transformers, predicates, monoids and keys). It is shared by
+// the in-memory evaluator and the stream interpreter of duct.
+package fn
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrorType is reflect type of error interface
+var ErrorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ValueOf returns reflect value of type t, nil is casted to zero value
+func ValueOf(x any, t reflect.Type) reflect.Value {
+	if x == nil {
+		return reflect.Zero(t)
+	}
+	return reflect.ValueOf(x)
+}
+
+// ArgOf returns reflect value of x as the argument of type t, nil is casted
+// to zero value. It fails if the value is not assignable to t.
+func ArgOf(x any, t reflect.Type) (reflect.Value, error) {
+	v := ValueOf(x, t)
+	if !v.Type().AssignableTo(t) {
+		return v, fmt.Errorf("value of type %T is not assignable to %s", x, t)
+	}
+	return v, nil
+}
+
+// Policy of failed transformer
+type Policy struct {
+	// Number of attempts, including the first one
+	Attempts int
+	// Delay before the attempt
+	Backoff func(attempt int) time.Duration
+	// Handler of failure func(error) (B, error)
+	Catch any
+}
+
+// Transformer returns function of transformer func(A) (B, error) with
+// the failure policy and the type B. Retries are cancelled with context.
+func Transformer(ctx context.Context, ta, tb string, f any, policy Policy) (func(any) (any, error), reflect.Type, error) {
+	fv := reflect.ValueOf(f)
+	if fv.Kind() != reflect.Func || fv.Type().NumIn() != 1 || fv.Type().NumOut() != 2 || fv.Type().Out(1) != ErrorType {
+		return nil, nil, fmt.Errorf("transformer %s ⟼ %s is not func(A) (B, error): %T", ta, tb, f)
+	}
+
+	var catch reflect.Value
+	if policy.Catch != nil {
+		catch = reflect.ValueOf(policy.Catch)
+		if catch.Kind() != reflect.Func || catch.Type().NumIn() != 1 || catch.Type().In(0) != ErrorType || catch.Type().NumOut() != 2 || catch.Type().Out(1) != ErrorType {
+			return nil, nil, fmt.Errorf("handler of %s ⟼ %s is not func(error) (B, error): %T", ta, tb, policy.Catch)
+		}
+	}
+
+	in := fv.Type().In(0)
+	apply := func(x reflect.Value) (any, error) {
+		out := fv.Call([]reflect.Value{x})
+		if !out[1].IsNil() {
+			return nil, out[1].Interface().(error)
+		}
+		return out[0].Interface(), nil
+	}
+
+	attempts := max(policy.Attempts, 1)
+
+	return func(x any) (any, error) {
+		arg, err := ArgOf(x, in)
+		if err != nil {
+			return nil, fmt.Errorf("transformer %s ⟼ %s: %w", ta, tb, err)
+		}
+
+		var y any
+		for i := 1; i <= attempts; i++ {
+			if i > 1 && policy.Backoff != nil {
+				t := time.NewTimer(policy.Backoff(i))
+				select {
+				case <-t.C:
+				case <-ctx.Done():
+					t.Stop()
+					return nil, ctx.Err()
+				}
+			}
+
+			if y, err = apply(arg); err == nil {
+				return y, nil
+			}
+		}
+
+		if catch.IsValid() {
+			out := catch.Call([]reflect.Value{reflect.ValueOf(&err).Elem()})
+			if !out[1].IsNil() {
+				return nil, out[1].Interface().(error)
+			}
+			return out[0].Interface(), nil
+		}
+
+		return nil, err
+	}, fv.Type().Out(0), nil
+}
+
+// Predicate returns function of predicate func(A) (bool, error)
+func Predicate(t string, pred any) (func(any) (bool, error), error) {
+	f := reflect.ValueOf(pred)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 1 || f.Type().NumOut() != 2 || f.Type().Out(0).Kind() != reflect.Bool || f.Type().Out(1) != ErrorType {
+		return nil, fmt.Errorf("predicate of %s is not func(A) (bool, error): %T", t, pred)
+	}
+
+	in := f.Type().In(0)
+	return func(x any) (bool, error) {
+		arg, err := ArgOf(x, in)
+		if err != nil {
+			return false, fmt.Errorf("predicate of %s: %w", t, err)
+		}
+
+		out := f.Call([]reflect.Value{arg})
+		if !out[1].IsNil() {
+			return false, out[1].Interface().(error)
+		}
+		return out[0].Bool(), nil
+	}, nil
+}
+
+// Monoid returns empty element and operation ƒ: A ⟼ A ⟼ A of monoid.Monoid
+// together with type A.
+func Monoid(t string, monoid any) (func() any, func(any, any) any, reflect.Type, error) {
+	m := reflect.ValueOf(monoid)
+	if !m.IsValid() {
+		return nil, nil, nil, fmt.Errorf("monoid of %s is not defined", t)
+	}
+
+	empty := m.MethodByName("Empty")
+	combine := m.MethodByName("Combine")
+	if !empty.IsValid() || !combine.IsValid() {
+		return nil, nil, nil, fmt.Errorf("monoid of %s is not monoid.Monoid: %T", t, monoid)
+	}
+
+	et, ct := empty.Type(), combine.Type()
+	if et.NumIn() != 0 || et.NumOut() != 1 || ct.NumIn() != 2 || ct.NumOut() != 1 || ct.In(0) != et.Out(0) || ct.In(1) != et.Out(0) || ct.Out(0) != et.Out(0) {
+		return nil, nil, nil, fmt.Errorf("monoid of %s is not monoid.Monoid: %T", t, monoid)
+	}
+
+	at := et.Out(0)
+	return func() any { return empty.Call(nil)[0].Interface() },
+		func(a, b any) any {
+			return combine.Call([]reflect.Value{ValueOf(a, at), ValueOf(b, at)})[0].Interface()
+		},
+		at,
+		nil
+}
+
+// Grouping returns key function func(A) (K, error) and type of groups map[K][]A
+func Grouping(t string, key any) (func(any) (any, error), reflect.Type, error) {
+	f := reflect.ValueOf(key)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 1 || f.Type().NumOut() != 2 || f.Type().Out(1) != ErrorType || !f.Type().Out(0).Comparable() {
+		return nil, nil, fmt.Errorf("key of %s is not func(A) (K, error): %T", t, key)
+	}
+
+	in := f.Type().In(0)
+	return func(x any) (any, error) {
+			arg, err := ArgOf(x, in)
+			if err != nil {
+				return nil, fmt.Errorf("key of %s: %w", t, err)
+			}
+
+			out := f.Call([]reflect.Value{arg})
+			if !out[1].IsNil() {
+				return nil, out[1].Interface().(error)
+			}
+			return out[0].Interface(), nil
+		},
+		reflect.MapOf(f.Type().Out(0), reflect.SliceOf(in)),
+		nil
+}
+
+// Group appends element to its group, groups is map[K][]A
+func Group(groups reflect.Value, key func(any) (any, error), x any) error {
+	k, err := key(x)
+	if err != nil {
+		return err
+	}
+
+	t := groups.Type()
+	kv := ValueOf(k, t.Key())
+	seq := groups.MapIndex(kv)
+	if !seq.IsValid() {
+		seq = reflect.MakeSlice(t.Elem(), 0, 1)
+	}
+	groups.SetMapIndex(kv, reflect.Append(seq, ValueOf(x, t.Elem().Elem())))
+	return nil
+}
+
+// Batch returns elements as slice of type t
+func Batch(t reflect.Type, xs []any) any {
+	eg := reflect.MakeSlice(t, len(xs), len(xs))
+	for i, x := range xs {
+		eg.Index(i).Set(ValueOf(x, t.Elem()))
+	}
+	return eg.Interface()
+}
+
+// Elements of slice or array
+func Elements(x any) ([]any, error) {
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("unable to lift %T into functor", x)
+	}
+
+	seq := make([]any, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		seq[i] = v.Index(i).Interface()
+	}
+	return seq, nil
+}
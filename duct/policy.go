@@ -0,0 +1,103 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RetryPolicy of failed transformer
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one
+	Attempts int `json:"attempts" yaml:"attempts"`
+	// Delay before the second attempt
+	Delay time.Duration `json:"delay,omitempty" yaml:"delay,omitempty"`
+	// Multiplier of delay for each following attempt, 1 if not defined
+	Factor float64 `json:"factor,omitempty" yaml:"factor,omitempty"`
+}
+
+// Backoff returns delay before the attempt, the first attempt is 1.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt <= 1 {
+		return 0
+	}
+
+	factor := p.Factor
+	if factor <= 0 {
+		factor = 1
+	}
+
+	return time.Duration(float64(p.Delay) * math.Pow(factor, float64(attempt-2)))
+}
+
+// Failure of the element, it is sent to the dead letter target
+type Failure struct {
+	// Input of failed transformer
+	Value any
+	Err   error
+}
+
+func (f Failure) Error() string { return fmt.Sprintf("failed %v: %s", f.Value, f.Err) }
+func (f Failure) Unwrap() error { return f.Err }
+
+// FailureOf returns the failure of element x. The failure of transformer
+// fused by FuseMap refers to the input of the failed transformer, not to x.
+func FailureOf(x any, err error) Failure {
+	var e *fusedError
+	if errors.As(err, &e) {
+		return Failure{Value: e.value, Err: e.err}
+	}
+	return Failure{Value: x, Err: err}
+}
+
+// failure of the transformer within the fused one, it keeps the input of
+// the transformer so that dead letter sees the same failure as without fusion.
+type fusedError struct {
+	value any
+	err   error
+}
+
+func (e *fusedError) Error() string { return e.err.Error() }
+func (e *fusedError) Unwrap() error { return e.err }
+
+// Retry the transformer 𝑓: A ⟼ B with the policy if it fails.
+func Retry[A, B any](policy RetryPolicy, f F[A, B]) F[A, B] {
+	f.retry = &policy
+	return f
+}
+
+// Catch failures of the last transformer of morphism 𝑚: A ⟼ B, the handler
+// 𝑓: error ⟼ B either recovers the value or fails again. The handler is
+// applied after retries are exhausted. It panics if morphism is not ended
+// with transformer (e.g. Unit or Yield).
+func Catch[A, B any](handler F[error, B], m Morphism[A, B]) Morphism[A, B] {
+	node, ok := m.code.last().(*AstMap)
+	if !ok {
+		panic(fmt.Errorf("morphism ⟼ %s is not ended with transformer", ShortTypeOf[B]()))
+	}
+
+	catch := *node
+	catch.Catch = handler.f
+	code := m.code.replace(&catch)
+
+	return Morphism[A, B]{code: code}
+}
+
+// DeadLetter sends failures of transformers within morphism 𝑚: A ⟼ B,
+// including branches, to the target instead of reporting them as errors.
+// The failed element is dropped from the morphism.
+func DeadLetter[A, B any](target T[Failure], m Morphism[A, B]) Morphism[A, B] {
+	code := m.code.with(m.code.Seq)
+	code.DeadLetter = target.v
+
+	return Morphism[A, B]{code: code}
+}
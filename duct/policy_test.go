@@ -0,0 +1,135 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/it/v2"
+)
+
+func TestPolicy(t *testing.T) {
+	flaky := func(n int) duct.F[int, int] {
+		return duct.L2[int, int](func(x int) (int, error) {
+			if n--; n >= 0 {
+				return 0, fmt.Errorf("flaky")
+			}
+			return x, nil
+		})
+	}
+
+	t.Run("Backoff", func(t *testing.T) {
+		p := duct.RetryPolicy{Attempts: 4, Delay: time.Second, Factor: 2}
+
+		it.Then(t).Should(
+			it.Equal(p.Backoff(1), 0),
+			it.Equal(p.Backoff(2), time.Second),
+			it.Equal(p.Backoff(3), 2*time.Second),
+			it.Equal(p.Backoff(4), 4*time.Second),
+		)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		m := duct.Join(duct.Retry(duct.RetryPolicy{Attempts: 3}, flaky(2)), duct.From(src))
+		v, err := duct.Eval(m, 10)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, 10),
+		)
+	})
+
+	t.Run("RetryExhausted", func(t *testing.T) {
+		m := duct.Join(duct.Retry(duct.RetryPolicy{Attempts: 2}, flaky(2)), duct.From(src))
+		_, err := duct.Eval(m, 10)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("flaky"),
+		)
+	})
+
+	t.Run("Catch", func(t *testing.T) {
+		h := duct.L2[error, int](func(error) (int, error) { return -1, nil })
+		m := duct.Catch(h, duct.Join(flaky(1), duct.From(src)))
+		v, err := duct.Eval(m, 10)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, -1),
+			it.Nil(duct.Validate(duct.Catch(h, duct.Join(flaky(1), duct.From(duct.L1[int]([]int{})))))),
+		)
+	})
+
+	t.Run("CatchUnit", func(t *testing.T) {
+		h := duct.L2[error, []int](func(error) ([]int, error) { return nil, nil })
+
+		defer func() {
+			it.Then(t).ShouldNot(
+				it.Nil(recover()),
+			)
+		}()
+
+		duct.Catch(h, duct.Unit(duct.WrapF(duct.Join(dup, duct.From(src)))))
+	})
+
+	t.Run("DeadLetter", func(t *testing.T) {
+		seq := make([]duct.Failure, 0)
+		dlq := duct.L1[duct.Failure](func(x duct.Failure) error {
+			seq = append(seq, x)
+			return nil
+		})
+
+		m := duct.DeadLetter(dlq, duct.Unit(duct.Join(atoi, duct.LiftF(itoa, duct.Join(dup, duct.From(src))))))
+		v, err := duct.Eval(m, 2)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal(2, 2),
+			it.Equal(len(seq), 0),
+		)
+
+		m = duct.DeadLetter(dlq,
+			duct.Unit(duct.LiftF(duct.L2[int, int](func(x int) (int, error) {
+				if x%2 == 0 {
+					return 0, fmt.Errorf("even")
+				}
+				return x, nil
+			}), duct.Join(duct.L2[int, []int](func(x int) ([]int, error) { return []int{x, x + 1, x + 2}, nil }), duct.From(src)))),
+		)
+		v, err = duct.Eval(m, 1)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal(1, 3),
+			it.Equal(len(seq), 1),
+			it.Equal(seq[0].Value.(int), 2),
+			it.Fail(func() error { return seq[0] }).Contain("even"),
+		)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		h := duct.L2[error, int](func(error) (string, error) { return "", nil })
+		m := duct.DeadLetter(duct.L1[duct.Failure](make(chan error)),
+			duct.Catch(h, duct.Join(duct.Retry(duct.RetryPolicy{}, flaky(1)), duct.From(src))),
+		)
+		err := duct.Validate(m)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Fail(func() error { return err }).Contain("dead letter of error does not match"),
+			it.Fail(func() error { return err }).Contain("retry attempts 0 is not positive"),
+			it.Fail(func() error { return err }).Contain("is not func(error) (int, error)"),
+		)
+	})
+}
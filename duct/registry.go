@@ -0,0 +1,113 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Registry of symbols, it binds names to values of sources, transformers,
+// predicates and targets used by morphisms. The registry resolves values to
+// names when morphism is encoded and names back to values when it is decoded.
+//
+// Values are registered either as is or lifted by L1 and L2. Values are
+// identified by reference: functions, channels, maps and pointers
+// by their address, slices by the address and length, other values by
+// equality. Note that closures built from the same function literal share
+// the address, they are not distinguishable.
+type Registry struct {
+	values  map[string]any
+	symbols map[symbol]string
+}
+
+// identity of the value
+type symbol struct {
+	t reflect.Type
+	p uintptr
+	n int
+	v any
+}
+
+// NewRegistry creates empty registry of symbols
+func NewRegistry() *Registry {
+	return &Registry{
+		values:  make(map[string]any),
+		symbols: make(map[symbol]string),
+	}
+}
+
+// Register binds the value x with name. Both the name and the value must be
+// unique within the registry, it fails if the value (or another value of
+// same identity, e.g. closure of same function literal) is already bound.
+// Use reflect.TypeFor[duct.Pair[A, B]]() to register the type of Zip results
+// and reflect.TypeFor[[]B]() for the type of Batch results.
+func (r *Registry) Register(name string, x any) error {
+	if _, has := r.values[name]; has {
+		return fmt.Errorf("symbol %s is already registered", name)
+	}
+
+	key, ok := symbolOf(x)
+	if !ok {
+		return fmt.Errorf("value of %T is not identifiable", x)
+	}
+
+	if other, has := r.symbols[key]; has {
+		return fmt.Errorf("value of %T is already registered as %s", x, other)
+	}
+
+	if l, ok := x.(lifted); ok {
+		x = l.value()
+	}
+
+	r.values[name] = x
+	r.symbols[key] = name
+	return nil
+}
+
+// Lookup value by name
+func (r *Registry) Lookup(name string) (any, bool) {
+	x, has := r.values[name]
+	return x, has
+}
+
+// Symbol returns name of the value
+func (r *Registry) Symbol(x any) (string, bool) {
+	key, ok := symbolOf(x)
+	if !ok {
+		return "", false
+	}
+
+	name, has := r.symbols[key]
+	return name, has
+}
+
+func symbolOf(x any) (symbol, bool) {
+	if l, ok := x.(lifted); ok {
+		x = l.value()
+	}
+
+	if x == nil {
+		return symbol{}, false
+	}
+
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Func, reflect.Chan, reflect.Map, reflect.Pointer, reflect.UnsafePointer:
+		return symbol{t: v.Type(), p: v.Pointer()}, true
+	case reflect.Slice:
+		return symbol{t: v.Type(), p: v.Pointer(), n: v.Len()}, true
+	}
+
+	if !v.Comparable() {
+		return symbol{}, false
+	}
+
+	return symbol{t: v.Type(), v: x}, true
+}
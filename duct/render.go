@@ -0,0 +1,302 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format of rendered morphism
+type Format int
+
+const (
+	// Indented textual form of morphism 𝑚: A ⟼ B, each node is ƒ: A ⟼ B
+	FormatText Format = iota
+	// Graphviz DOT
+	FormatDot
+	// Mermaid flowchart
+	FormatMermaid
+)
+
+// Render the morphism 𝑚: A ⟼ B into the format. Nested contexts (LiftF, WrapF,
+// Unit) and branches (Fork, Merge, Zip, Switch) are rendered as subgraphs.
+func Render[A, B any](m Morphism[A, B], format Format) (string, error) {
+	switch format {
+	case FormatText:
+		return renderText(m.code), nil
+	case FormatDot:
+		return renderDot(graphOf(m.code)), nil
+	case FormatMermaid:
+		return renderMermaid(graphOf(m.code)), nil
+	default:
+		return "", fmt.Errorf("unsupported format %d", format)
+	}
+}
+
+// type of morphism 𝑚: A ⟼ B for the input of type t
+func typeOfMorphism(node *AstSeq, t string) (string, string) {
+	if len(node.Seq) > 0 && isFrom(node.Seq[0]) {
+		t = "ø"
+	}
+
+	v := &validator{}
+	return t, v.seq(nil, node, t, false, true)
+}
+
+//------------------------------------------------------------------------------
+
+func renderText(node *AstSeq) string {
+	sb := &strings.Builder{}
+	textSeq(sb, 0, node, "")
+	return sb.String()
+}
+
+func textSeq(sb *strings.Builder, depth int, node *AstSeq, t string) {
+	if node.Root {
+		a, b := typeOfMorphism(node, t)
+		fmt.Fprintf(sb, "%s𝑚: %s ⟼ %s\n", strings.Repeat("\t", depth), ShortType(a), ShortType(b))
+	}
+
+	for _, x := range node.Seq {
+		s := strings.Repeat("\t", depth+1)
+
+		switch n := x.(type) {
+		case *AstSeq:
+			textSeq(sb, depth+1, n, "")
+		case *AstFork:
+			fmt.Fprintf(sb, "%s%s\n", s, signature(n))
+			for _, b := range n.Branches {
+				textSeq(sb, depth+2, b, n.Type)
+			}
+		case *AstMerge:
+			fmt.Fprintf(sb, "%s%s\n", s, signature(n))
+			for _, b := range n.Branches {
+				textSeq(sb, depth+2, b, n.TypeA)
+			}
+		case *AstZip:
+			fmt.Fprintf(sb, "%s%s\n", s, signature(n))
+			for _, b := range n.Branches {
+				textSeq(sb, depth+2, b, n.TypeA)
+			}
+		case *AstSwitch:
+			fmt.Fprintf(sb, "%s%s\n", s, signature(n))
+			for _, c := range n.Cases {
+				textSeq(sb, depth+2, c.Branch, c.Type)
+			}
+		default:
+			fmt.Fprintf(sb, "%s%s\n", s, signature(n))
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// shapes of graph nodes
+const (
+	shapeSource = iota
+	shapeSink
+	shapeMap
+	shapeFilter
+	shapeSplit
+	shapeJoin
+)
+
+type vertex struct {
+	id, label string
+	shape     int
+}
+
+// subgraph of vertexes
+type cluster struct {
+	id, label string
+	vertexes  []vertex
+	clusters  []*cluster
+}
+
+type graph struct {
+	root  *cluster
+	edges [][2]string
+	id    int
+}
+
+func graphOf(node *AstSeq) *graph {
+	g := &graph{root: &cluster{}}
+	g.seq(g.root, node, "", nil)
+	return g
+}
+
+func (g *graph) vertex(c *cluster, label string, shape int, prev []string) string {
+	g.id++
+	id := fmt.Sprintf("n%d", g.id)
+	c.vertexes = append(c.vertexes, vertex{id: id, label: ShortType(label), shape: shape})
+
+	for _, p := range prev {
+		g.edges = append(g.edges, [2]string{p, id})
+	}
+	return id
+}
+
+func (g *graph) cluster(c *cluster, label string) *cluster {
+	g.id++
+	sub := &cluster{id: fmt.Sprintf("cluster_%d", g.id), label: ShortType(label)}
+	c.clusters = append(c.clusters, sub)
+	return sub
+}
+
+// builds graph of sequence for the input of type t, connecting it with prev
+// vertexes. It returns type of output and tail vertexes of the sequence.
+func (g *graph) seq(c *cluster, node *AstSeq, t string, prev []string) (string, []string) {
+	for _, x := range node.Seq {
+		switch n := x.(type) {
+		case *AstFrom:
+			t = n.Type
+			prev = []string{g.vertex(c, "source: "+n.Type, shapeSource, prev)}
+		case *AstMap:
+			t = n.TypeB
+			prev = []string{g.vertex(c, fmt.Sprintf("ƒ: %s ⟼ %s", n.TypeA, n.TypeB), shapeMap, prev)}
+		case *AstYield:
+			t = "ø"
+			prev = []string{g.vertex(c, "sink: "+n.Type, shapeSink, prev)}
+		case *AstFilter:
+			prev = []string{g.vertex(c, "filter: "+n.Type, shapeFilter, prev)}
+		case *AstSeq:
+			elem := strings.TrimPrefix(t, "[]")
+			label := fmt.Sprintf("𝓕⁺(%s)", elem)
+			if !n.Deferred {
+				label = fmt.Sprintf("𝓕(%s)", elem)
+			}
+
+			var inner string
+			inner, prev = g.seq(g.cluster(c, label), n, elem, prev)
+			if n.Deferred {
+				t = inner
+			} else {
+				t = "[]" + inner
+			}
+		case *AstFork:
+			split := g.vertex(c, "fork: "+n.Type, shapeSplit, prev)
+			g.branches(c, n.Branches, n.Type, split)
+			t, prev = "ø", nil
+		case *AstMerge:
+			split := g.vertex(c, "merge: "+n.TypeA, shapeSplit, prev)
+			tails := g.branches(c, n.Branches, n.TypeA, split)
+			t, prev = n.TypeB, []string{g.vertex(c, n.TypeB, shapeJoin, tails)}
+		case *AstZip:
+			split := g.vertex(c, "zip: "+n.TypeA, shapeSplit, prev)
+			tails := g.branches(c, n.Branches, n.TypeA, split)
+			t, prev = n.TypeB, []string{g.vertex(c, n.TypeB, shapeJoin, tails)}
+		case *AstSwitch:
+			split := g.vertex(c, "switch: "+n.TypeA, shapeSplit, prev)
+			tails := make([]string, 0)
+			for _, cs := range n.Cases {
+				tails = append(tails, g.branch(c, cs.Branch, cs.Type, split)...)
+			}
+			t, prev = n.TypeB, []string{g.vertex(c, n.TypeB, shapeJoin, tails)}
+		case *AstFold:
+			prev = []string{g.vertex(c, "fold: "+n.Type, shapeJoin, prev)}
+		case *AstBatch:
+			t = "[]" + n.Type
+			prev = []string{g.vertex(c, "batch: "+n.Type, shapeJoin, prev)}
+		case *AstGroupBy:
+			t = n.TypeB
+			prev = []string{g.vertex(c, "group: "+n.TypeA, shapeJoin, prev)}
+		}
+	}
+
+	return t, prev
+}
+
+func (g *graph) branches(c *cluster, branches []*AstSeq, t string, split string) []string {
+	tails := make([]string, 0)
+	for _, b := range branches {
+		tails = append(tails, g.branch(c, b, t, split)...)
+	}
+	return tails
+}
+
+func (g *graph) branch(c *cluster, node *AstSeq, t string, split string) []string {
+	a, b := typeOfMorphism(node, t)
+	_, tails := g.seq(g.cluster(c, fmt.Sprintf("𝑚: %s ⟼ %s", a, b)), node, t, []string{split})
+	return tails
+}
+
+//------------------------------------------------------------------------------
+
+var dotShapes = map[int]string{
+	shapeSource: "invhouse",
+	shapeSink:   "house",
+	shapeMap:    "box",
+	shapeFilter: "diamond",
+	shapeSplit:  "trapezium",
+	shapeJoin:   "invtrapezium",
+}
+
+func renderDot(g *graph) string {
+	sb := &strings.Builder{}
+	sb.WriteString("digraph {\n\trankdir=LR;\n")
+	dotCluster(sb, 1, g.root)
+	for _, e := range g.edges {
+		fmt.Fprintf(sb, "\t%s -> %s;\n", e[0], e[1])
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func dotCluster(sb *strings.Builder, depth int, c *cluster) {
+	s := strings.Repeat("\t", depth)
+	for _, v := range c.vertexes {
+		fmt.Fprintf(sb, "%s%s [label=%q, shape=%s];\n", s, v.id, v.label, dotShapes[v.shape])
+	}
+
+	for _, sub := range c.clusters {
+		fmt.Fprintf(sb, "%ssubgraph %s {\n%s\tlabel=%q;\n", s, sub.id, s, sub.label)
+		dotCluster(sb, depth+1, sub)
+		fmt.Fprintf(sb, "%s}\n", s)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var mermaidShapes = map[int][2]string{
+	shapeSource: {"([", "])"},
+	shapeSink:   {"[(", ")]"},
+	shapeMap:    {"[", "]"},
+	shapeFilter: {"{", "}"},
+	shapeSplit:  {"[/", "\\]"},
+	shapeJoin:   {"[\\", "/]"},
+}
+
+func renderMermaid(g *graph) string {
+	sb := &strings.Builder{}
+	sb.WriteString("flowchart LR\n")
+	mermaidCluster(sb, 1, g.root)
+	for _, e := range g.edges {
+		fmt.Fprintf(sb, "\t%s --> %s\n", e[0], e[1])
+	}
+	return sb.String()
+}
+
+func mermaidCluster(sb *strings.Builder, depth int, c *cluster) {
+	s := strings.Repeat("\t", depth)
+	for _, v := range c.vertexes {
+		shape := mermaidShapes[v.shape]
+		fmt.Fprintf(sb, "%s%s%s\"%s\"%s\n", s, v.id, shape[0], mermaidLabel(v.label), shape[1])
+	}
+
+	for _, sub := range c.clusters {
+		fmt.Fprintf(sb, "%ssubgraph %s [\"%s\"]\n", s, sub.id, mermaidLabel(sub.label))
+		mermaidCluster(sb, depth+1, sub)
+		fmt.Fprintf(sb, "%send\n", s)
+	}
+}
+
+func mermaidLabel(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
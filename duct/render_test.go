@@ -0,0 +1,83 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/it/v2"
+)
+
+func TestRender(t *testing.T) {
+	m := duct.Yield(cs, duct.Unit(duct.LiftF(fBC, duct.Join(fABs, duct.From(a)))))
+
+	t.Run("Text", func(t *testing.T) {
+		for expect, spec := range map[string]duct.Morphism[A, duct.Void]{
+			`𝑚: ø ⟼ ø
+	ƒ: ø ⟼ A
+	ƒ: A ⟼ []B
+		ƒ: B ⟼ C
+	ƒ: []C ⟼ ø
+`: m,
+			`𝑚: ø ⟼ ø
+	ƒ: ø ⟼ A
+	ƒ: A ⟼ (ø, ..., ø)
+		𝑚: A ⟼ ø
+			ƒ: A ⟼ ø
+		𝑚: A ⟼ ø
+			ƒ: A ⟼ B
+			ƒ: B ⟼ ø
+`: duct.Fork(duct.From(a),
+				func(m duct.Morphism[A, A]) duct.Morphism[A, duct.Void] { return duct.Yield(a, m) },
+				func(m duct.Morphism[A, A]) duct.Morphism[A, duct.Void] { return duct.Yield(b, duct.Join(fAB, m)) },
+			),
+		} {
+			txt, err := duct.Render(spec, duct.FormatText)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(txt, expect),
+			)
+		}
+	})
+
+	t.Run("Dot", func(t *testing.T) {
+		dot, err := duct.Render(m, duct.FormatDot)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.HasPrefix(dot, "digraph {")),
+			it.True(strings.Contains(dot, `n1 [label="source: A", shape=invhouse];`)),
+			it.True(strings.Contains(dot, `subgraph cluster_3 {`)),
+			it.True(strings.Contains(dot, `label="𝓕(B)";`)),
+			it.True(strings.Contains(dot, `n5 [label="sink: []C", shape=house];`)),
+			it.True(strings.Contains(dot, "n2 -> n4;")),
+			it.True(strings.Contains(dot, "n4 -> n5;")),
+		)
+	})
+
+	t.Run("Mermaid", func(t *testing.T) {
+		mmd, err := duct.Render(m, duct.FormatMermaid)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(strings.HasPrefix(mmd, "flowchart LR\n")),
+			it.True(strings.Contains(mmd, `n1(["source: A"])`)),
+			it.True(strings.Contains(mmd, `subgraph cluster_3 ["𝓕(B)"]`)),
+			it.True(strings.Contains(mmd, `n5[("sink: []C")]`)),
+			it.True(strings.Contains(mmd, "n4 --> n5")),
+		)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := duct.Render(m, duct.Format(-1))
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
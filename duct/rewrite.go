@@ -0,0 +1,223 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"reflect"
+)
+
+// Pass is the rewrite rule of AST. It transforms nodes of the sequence into
+// semantically equivalent ones, the type of sequence output is preserved.
+type Pass func(seq []Ast) []Ast
+
+// Rewrite the morphism 𝑚: A ⟼ B with passes. Passes are applied in the given
+// order, each pass is applied to every sequence of the morphism, including
+// nested contexts and branches. The original morphism is not modified.
+//
+// Note: rewritten transformers are Go functions composed at runtime, they are
+// not known by the Registry. Rewrite the morphism after its serialization.
+func Rewrite[A, B any](m Morphism[A, B], passes ...Pass) Morphism[A, B] {
+	code := cloneSeq(m.code)
+	for _, pass := range passes {
+		rewriteSeq(code, pass)
+	}
+
+	return Morphism[A, B]{code: code}
+}
+
+func rewriteSeq(node *AstSeq, pass Pass) {
+	for _, x := range node.Seq {
+		switch n := x.(type) {
+		case *AstSeq:
+			rewriteSeq(n, pass)
+		case *AstFork:
+			rewriteBranches(n.Branches, pass)
+		case *AstMerge:
+			rewriteBranches(n.Branches, pass)
+		case *AstZip:
+			rewriteBranches(n.Branches, pass)
+		case *AstSwitch:
+			for _, c := range n.Cases {
+				rewriteSeq(c.Branch, pass)
+			}
+		}
+	}
+
+	node.Seq = pass(node.Seq)
+}
+
+func rewriteBranches(branches []*AstSeq, pass Pass) {
+	for _, b := range branches {
+		rewriteSeq(b, pass)
+	}
+}
+
+// deep copy of AST, values of nodes (e.g. functions) are shared
+func cloneSeq(node *AstSeq) *AstSeq {
+	seq := *node
+	seq.Seq = make([]Ast, len(node.Seq))
+	for i, x := range node.Seq {
+		seq.Seq[i] = cloneNode(x)
+	}
+	return &seq
+}
+
+func cloneBranches(branches []*AstSeq) []*AstSeq {
+	seq := make([]*AstSeq, len(branches))
+	for i, b := range branches {
+		seq[i] = cloneSeq(b)
+	}
+	return seq
+}
+
+func cloneNode(node Ast) Ast {
+	switch n := node.(type) {
+	case *AstSeq:
+		return cloneSeq(n)
+	case *AstFrom:
+		c := *n
+		return &c
+	case *AstMap:
+		c := *n
+		return &c
+	case *AstYield:
+		c := *n
+		return &c
+	case *AstFilter:
+		c := *n
+		return &c
+	case *AstFold:
+		c := *n
+		return &c
+	case *AstBatch:
+		c := *n
+		return &c
+	case *AstGroupBy:
+		c := *n
+		return &c
+	case *AstFork:
+		c := *n
+		c.Branches = cloneBranches(n.Branches)
+		return &c
+	case *AstMerge:
+		c := *n
+		c.Branches = cloneBranches(n.Branches)
+		return &c
+	case *AstZip:
+		c := *n
+		c.Branches = cloneBranches(n.Branches)
+		return &c
+	case *AstSwitch:
+		c := *n
+		c.Cases = make([]AstCase, len(n.Cases))
+		for i, x := range n.Cases {
+			c.Cases[i] = AstCase{Type: x.Type, F: x.F, Branch: cloneSeq(x.Branch)}
+		}
+		return &c
+	default:
+		return node
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// FuseMap fuses consecutive transformers ƒ: A ⟼ B and g: B ⟼ C into
+// the single one g ∘ ƒ: A ⟼ C. Transformers with failure policy or attributes
+// are not fused. Failures of fused transformer are sent to the dead letter
+// with the input of failed one, see FailureOf.
+func FuseMap(seq []Ast) []Ast {
+	out := make([]Ast, 0, len(seq))
+	for _, x := range seq {
+		if len(out) > 0 {
+			f, okf := out[len(out)-1].(*AstMap)
+			g, okg := x.(*AstMap)
+			if okf && okg {
+				if h, ok := fuse(f, g); ok {
+					out[len(out)-1] = h
+					continue
+				}
+			}
+		}
+		out = append(out, x)
+	}
+	return out
+}
+
+func fuse(f, g *AstMap) (*AstMap, bool) {
+	if f.Retry != nil || f.Catch != nil || g.Retry != nil || g.Catch != nil || !f.Attrs.IsZero() || !g.Attrs.IsZero() {
+		return nil, false
+	}
+
+	fv, gv := reflect.ValueOf(f.F), reflect.ValueOf(g.F)
+	if !isTransformer(fv) || !isTransformer(gv) || !fv.Type().Out(0).AssignableTo(gv.Type().In(0)) {
+		return nil, false
+	}
+
+	ht := reflect.FuncOf(
+		[]reflect.Type{fv.Type().In(0)},
+		[]reflect.Type{gv.Type().Out(0), typeError},
+		false,
+	)
+
+	h := reflect.MakeFunc(ht, func(args []reflect.Value) []reflect.Value {
+		out := fv.Call(args)
+		if !out[1].IsNil() {
+			return []reflect.Value{reflect.Zero(ht.Out(0)), out[1]}
+		}
+		ret := gv.Call(out[:1])
+		if !ret[1].IsNil() {
+			err := &fusedError{value: out[0].Interface(), err: ret[1].Interface().(error)}
+			return []reflect.Value{ret[0], reflect.ValueOf(error(err))}
+		}
+		return ret
+	})
+
+	return &AstMap{TypeA: f.TypeA, TypeB: g.TypeB, F: h.Interface()}, true
+}
+
+func isTransformer(f reflect.Value) bool {
+	return f.Kind() == reflect.Func && f.Type().NumIn() == 1 && f.Type().NumOut() == 2 && f.Type().Out(1) == typeError
+}
+
+// DropEmptySeq drops closed contexts without inner stages, Unit(WrapF(𝑚))
+// is equivalent to 𝑚. Open contexts are kept, they flatten elements.
+func DropEmptySeq(seq []Ast) []Ast {
+	out := make([]Ast, 0, len(seq))
+	for _, x := range seq {
+		if n, ok := x.(*AstSeq); ok && !n.Root && !n.Deferred && len(n.Seq) == 0 {
+			continue
+		}
+		out = append(out, x)
+	}
+	return out
+}
+
+// FlattenUnit flattens consecutive closed contexts into single one, it
+// follows the functor law 𝓕(g) ∘ 𝓕(ƒ) = 𝓕(g ∘ ƒ). For example,
+// Unit(LiftF(g, Unit(LiftF(ƒ, 𝑚)))) is equivalent to Unit(LiftF(g ∘ ƒ, 𝑚)).
+func FlattenUnit(seq []Ast) []Ast {
+	out := make([]Ast, 0, len(seq))
+	for _, x := range seq {
+		if len(out) > 0 {
+			a, oka := out[len(out)-1].(*AstSeq)
+			b, okb := x.(*AstSeq)
+			if oka && okb && isClosed(a) && isClosed(b) {
+				c := &AstSeq{Seq: append(append(make([]Ast, 0, len(a.Seq)+len(b.Seq)), a.Seq...), b.Seq...)}
+				out[len(out)-1] = c
+				continue
+			}
+		}
+		out = append(out, x)
+	}
+	return out
+}
+
+func isClosed(node *AstSeq) bool {
+	return !node.Root && !node.Deferred
+}
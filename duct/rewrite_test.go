@@ -0,0 +1,136 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/it/v2"
+)
+
+func TestRewrite(t *testing.T) {
+	text := func(m duct.Morphism[int, []int]) string {
+		s, _ := duct.Render(m, duct.FormatText)
+		return s
+	}
+
+	t.Run("FuseMap", func(t *testing.T) {
+		m := duct.Join(atoi, duct.Join(itoa, duct.From(src)))
+		r := duct.Rewrite(m, duct.FuseMap)
+		v, err := duct.Eval(r, 10)
+
+		s, _ := duct.Render(r, duct.FormatText)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, 10),
+			it.Equal(s, "𝑚: ø ⟼ int\n\tƒ: ø ⟼ int\n\tƒ: int ⟼ int\n"),
+		)
+	})
+
+	t.Run("FuseMapErr", func(t *testing.T) {
+		fail := duct.L2[string, int](func(string) (int, error) { return 0, fmt.Errorf("fail") })
+		r := duct.Rewrite(duct.Join(fail, duct.Join(itoa, duct.From(src))), duct.FuseMap)
+		_, err := duct.Eval(r, 10)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("fail"),
+		)
+	})
+
+	t.Run("FuseMapDeadLetter", func(t *testing.T) {
+		seq := make([]duct.Failure, 0)
+		dlq := duct.L1[duct.Failure](func(x duct.Failure) error {
+			seq = append(seq, x)
+			return nil
+		})
+
+		fail := duct.L2[string, int](func(string) (int, error) { return 0, fmt.Errorf("fail") })
+		m := duct.DeadLetter(dlq, duct.Unit(duct.Join(fail, duct.LiftF(itoa, duct.Join(dup, duct.From(src))))))
+		r := duct.Rewrite(m, duct.FuseMap)
+		v, err := duct.Eval(r, 2)
+
+		s, _ := duct.Render(r, duct.FormatText)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(v), 0),
+			it.Equal(strings.Count(s, "ƒ:"), 3),
+			it.Equal(len(seq), 2),
+			it.Equal(seq[0].Value.(string), "2"),
+			it.Equal(seq[0].Err.Error(), "fail"),
+		)
+	})
+
+	t.Run("FuseMapPolicy", func(t *testing.T) {
+		m := duct.Join(duct.Retry(duct.RetryPolicy{Attempts: 2}, atoi), duct.Join(itoa, duct.From(src)))
+		r := duct.Rewrite(m, duct.FuseMap)
+
+		s, _ := duct.Render(r, duct.FormatText)
+		it.Then(t).Should(
+			it.Equal(strings.Count(s, "ƒ:"), 3),
+		)
+	})
+
+	t.Run("DropEmptySeq", func(t *testing.T) {
+		m := duct.Unit(duct.WrapF(duct.Join(dup, duct.From(src))))
+		r := duct.Rewrite(m, duct.DropEmptySeq)
+		v, err := duct.Eval(r, 2)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal(2, 2),
+			it.Equal(text(r), "𝑚: ø ⟼ []int\n\tƒ: ø ⟼ int\n\tƒ: int ⟼ []int\n"),
+		)
+	})
+
+	t.Run("FlattenUnit", func(t *testing.T) {
+		m := duct.Unit(duct.LiftF(itoa, duct.Unit(duct.LiftF(atoi, duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(src))))))))
+		r := duct.Rewrite(m, duct.FlattenUnit, duct.FuseMap)
+		v, err := duct.Eval(r, 2)
+		w, _ := duct.Eval(m, 2)
+
+		s, _ := duct.Render(r, duct.FormatText)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(v).Equal(w...),
+			it.Seq(v).Equal("2", "2"),
+			it.Equal(strings.Count(s, "ƒ:"), 3),
+		)
+	})
+
+	t.Run("Branches", func(t *testing.T) {
+		dot := func(m duct.Morphism[int, []int]) string {
+			s, _ := duct.Render(m, duct.FormatDot)
+			return s
+		}
+
+		m := duct.Merge(duct.Join(dup, duct.From(src)),
+			func(m duct.Morphism[[]int, []int]) duct.Morphism[[]int, []int] {
+				return duct.Unit(duct.WrapF(m))
+			},
+		)
+		r := duct.Rewrite(m, duct.DropEmptySeq)
+
+		it.Then(t).ShouldNot(
+			it.Equal(dot(m), dot(r)),
+		)
+	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		m := duct.Unit(duct.WrapF(duct.Join(dup, duct.From(src))))
+		s := text(m)
+		duct.Rewrite(m, duct.DropEmptySeq, duct.FlattenUnit, duct.FuseMap)
+
+		it.Then(t).Should(
+			it.Equal(text(m), s),
+		)
+	})
+}
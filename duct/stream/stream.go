@@ -0,0 +1,936 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+// Package stream is the reference interpreter of duct morphisms. It compiles
+// the morphism into running pipeline of pipe (or fork) stages:
+//   - AstFrom source is a channel (or slice) of elements of category A;
+//   - AstMap transformer is a function func(A) (B, error), executed by pipe.Map,
+//     it is retried and its failures are caught as defined by the node;
+//   - AstSeq, built by LiftF or WrapF, flattens the elements of []A;
+//   - AstSeq, closed by Unit, collects the elements back into []B;
+//   - AstYield target is a channel or a function func(B) error;
+//   - AstFork and AstMerge broadcast elements to branches, outputs of
+//     branches are joined;
+//   - AstZip applies branches element-wise, results are united into pair;
+//   - AstFilter predicate is a function func(A) (bool, error);
+//   - AstSwitch routes elements to branches of matching cases;
+//   - AstFold reduces elements with monoid using pipe.Fold, AstBatch and
+//     AstGroupBy collect elements into batches and groups. Aggregation
+//     applies to the whole stream or to the elements of closed sequence,
+//     it is not supported by element-wise branches (e.g. zip);
+//   - parallelism attribute of AstMap and AstYield overrides the number of
+//     workers of the stage;
+//   - failed elements are sent to the dead letter target of morphism, if defined,
+//     instead of the error channel.
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/golem/duct/internal/fn"
+	"github.com/fogfish/golem/pipe/v2"
+	"github.com/fogfish/golem/pipe/v2/fork"
+	"github.com/fogfish/golem/pure/monoid"
+)
+
+// Run compiles the morphism 𝑚: A ⟼ B into the pipeline and starts it.
+// It returns the channel of morphism results and the channel of errors.
+// Failed elements are reported to the error channel and dropped from
+// the pipeline. Stages are executed by par workers. The morphism is
+// validated before compilation, see duct.Validate.
+func Run[A, B any](ctx context.Context, par int, m duct.Morphism[A, B]) (<-chan B, <-chan error, error) {
+	if err := duct.Validate(m); err != nil {
+		return nil, nil, err
+	}
+
+	c := New(ctx, par)
+	if err := m.Apply(c); err != nil {
+		return nil, nil, err
+	}
+
+	out, exx := pipe.Map(ctx, c.Output(),
+		pipe.Try(func(x any) (B, error) {
+			v, ok := x.(B)
+			// Note: nil value of interface type B is not assertable, it is zero value
+			if !ok && x != nil {
+				return v, &duct.TypeError{Type: duct.TypeOf[B](), Value: x}
+			}
+			return v, nil
+		}),
+	)
+
+	return out, pipe.Join(ctx, append(c.exx, exx)...), nil
+}
+
+// Compiler is the visitor that compiles morphism into pipeline.
+type Compiler struct {
+	ctx   context.Context
+	par   int
+	scope []*scope
+	fork  []*branches
+	exx   []<-chan error
+	out   <-chan any
+	dlq   func(any) error
+}
+
+var _ duct.Visitor = (*Compiler)(nil)
+
+// element is skipped by element-wise synchronous function
+var errSkip = errors.New("skip")
+
+// compilation scope, either streaming or element-wise synchronous.
+// Synchronous scope with aggregation applies agg to all elements
+// before the element-wise function.
+type scope struct {
+	stream bool
+	ch     <-chan any
+	agg    func([]any) ([]any, error)
+	f      func(any) (any, error)
+	t      reflect.Type
+}
+
+// compilation of branches, streaming branches consumes broadcasted inputs,
+// synchronous branches are element-wise functions
+type branches struct {
+	stream bool
+	in     []<-chan any
+	out    []<-chan any
+	fs     []func(any) (any, error)
+	ts     []reflect.Type
+}
+
+// New creates the compiler, stages are executed by par workers.
+func New(ctx context.Context, par int) *Compiler {
+	return &Compiler{ctx: ctx, par: max(par, 1)}
+}
+
+// Output channel of compiled morphism
+func (c *Compiler) Output() <-chan any { return c.out }
+
+// Errors channel of compiled morphism
+func (c *Compiler) Errors() <-chan error { return pipe.Join(c.ctx, c.exx...) }
+
+func (c *Compiler) top() *scope { return c.scope[len(c.scope)-1] }
+
+func (c *Compiler) OnEnterMorphism(depth int, node duct.AstSeq) error {
+	if len(c.scope) == 0 {
+		if node.DeadLetter != nil {
+			f, err := c.sink(duct.TypeOf[duct.Failure](), node.DeadLetter)
+			if err != nil {
+				return err
+			}
+			c.dlq = f
+		}
+
+		c.scope = append(c.scope, &scope{stream: true})
+		return nil
+	}
+
+	// branch of fork, merge or zip
+	b := c.fork[len(c.fork)-1]
+	if b.stream {
+		c.scope = append(c.scope, &scope{stream: true, ch: b.in[len(b.out)]})
+		return nil
+	}
+
+	c.scope = append(c.scope, &scope{stream: false, f: func(x any) (any, error) { return x, nil }})
+	return nil
+}
+
+func (c *Compiler) OnLeaveMorphism(depth int, node duct.AstSeq) error {
+	inner := c.top()
+	c.scope = c.scope[:len(c.scope)-1]
+
+	if len(c.scope) == 0 {
+		c.out = inner.ch
+		return nil
+	}
+
+	b := c.fork[len(c.fork)-1]
+	if b.stream {
+		b.out = append(b.out, inner.ch)
+		return nil
+	}
+
+	if inner.agg != nil {
+		return fmt.Errorf("aggregation is not supported within element-wise branch at depth %d", depth)
+	}
+
+	b.fs = append(b.fs, inner.f)
+	b.ts = append(b.ts, inner.t)
+	return nil
+}
+
+func (c *Compiler) OnEnterSeq(depth int, node duct.AstSeq) error {
+	top := c.top()
+
+	if node.Deferred {
+		if !top.stream {
+			return fmt.Errorf("deferred sequence is not supported within closed one at depth %d", depth)
+		}
+
+		c.scope = append(c.scope, &scope{stream: true, ch: c.flatten(top.ch)})
+		return nil
+	}
+
+	c.scope = append(c.scope, &scope{stream: false, f: func(x any) (any, error) { return x, nil }})
+	return nil
+}
+
+func (c *Compiler) OnLeaveSeq(depth int, node duct.AstSeq) error {
+	inner := c.top()
+	c.scope = c.scope[:len(c.scope)-1]
+	top := c.top()
+
+	if inner.stream {
+		top.ch = inner.ch
+		return nil
+	}
+
+	// closed sequence is element-wise function over []A
+	agg, f, t := inner.agg, inner.f, inner.t
+	g := func(x any) (any, error) {
+		seq := reflect.ValueOf(x)
+
+		// Note: type is not known if sequence does not transform elements
+		et := t
+		if et == nil {
+			et = seq.Type().Elem()
+		}
+
+		xs := make([]any, seq.Len())
+		for i := range xs {
+			xs[i] = seq.Index(i).Interface()
+		}
+
+		if agg != nil {
+			var err error
+			if xs, err = agg(xs); err != nil {
+				return nil, err
+			}
+		}
+
+		eg := reflect.MakeSlice(reflect.SliceOf(et), 0, len(xs))
+		for _, x := range xs {
+			v, err := f(x)
+			if errors.Is(err, errSkip) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			eg = reflect.Append(eg, fn.ValueOf(v, et))
+		}
+		return eg.Interface(), nil
+	}
+
+	if top.stream {
+		top.ch = c.mapStage(c.par, top.ch, g)
+		return nil
+	}
+
+	top.f = compose(top.f, g)
+	if t != nil {
+		top.t = reflect.SliceOf(t)
+	}
+	return nil
+}
+
+func (c *Compiler) OnEnterMap(depth int, node duct.AstMap) error {
+	f, t, err := c.function(node)
+	if err != nil {
+		return err
+	}
+
+	top := c.top()
+	if top.stream {
+		top.ch = c.mapStage(c.parallelism(node.Attrs), top.ch, f)
+		return nil
+	}
+
+	top.f, top.t = compose(top.f, f), t
+	return nil
+}
+
+func (c *Compiler) OnLeaveMap(depth int, node duct.AstMap) error { return nil }
+
+func (c *Compiler) OnEnterFrom(depth int, node duct.AstFrom) error {
+	ch, err := c.source(node)
+	if err != nil {
+		return err
+	}
+
+	c.top().ch = ch
+	return nil
+}
+
+func (c *Compiler) OnLeaveFrom(depth int, node duct.AstFrom) error { return nil }
+
+func (c *Compiler) OnEnterYield(depth int, node duct.AstYield) error {
+	f, err := c.target(node)
+	if err != nil {
+		return err
+	}
+
+	top := c.top()
+	if top.stream {
+		top.ch = c.yieldStage(c.parallelism(node.Attrs), top.ch, f)
+		return nil
+	}
+
+	top.f = compose(top.f, func(x any) (any, error) { return nil, f(x) })
+	top.t = reflect.TypeOf(new(duct.Void)).Elem()
+	return nil
+}
+
+func (c *Compiler) OnLeaveYield(depth int, node duct.AstYield) error { return nil }
+
+func (c *Compiler) OnEnterFork(depth int, node duct.AstFork) error {
+	c.broadcast(len(node.Branches), c.top().stream)
+	return nil
+}
+
+func (c *Compiler) OnLeaveFork(depth int, node duct.AstFork) error {
+	b := c.leaveBranches()
+
+	top := c.top()
+	if top.stream {
+		top.ch = pipe.Join(c.ctx, b.out...)
+		return nil
+	}
+
+	top.f = compose(top.f, func(x any) (any, error) {
+		for _, f := range b.fs {
+			if _, err := f(x); err != nil && !errors.Is(err, errSkip) {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	top.t = reflect.TypeOf(new(duct.Void)).Elem()
+	return nil
+}
+
+func (c *Compiler) OnEnterMerge(depth int, node duct.AstMerge) error {
+	if !c.top().stream {
+		return fmt.Errorf("merge is not supported within closed sequence at depth %d", depth)
+	}
+
+	c.broadcast(len(node.Branches), true)
+	return nil
+}
+
+func (c *Compiler) OnLeaveMerge(depth int, node duct.AstMerge) error {
+	b := c.leaveBranches()
+	c.top().ch = pipe.Join(c.ctx, b.out...)
+	return nil
+}
+
+func (c *Compiler) OnEnterZip(depth int, node duct.AstZip) error {
+	if len(node.Branches) != 2 || node.Pair == nil {
+		return fmt.Errorf("zip ⟼ %s requires two branches", node.TypeB)
+	}
+
+	// Note: zip is element-wise, branches are compiled to functions
+	c.fork = append(c.fork, &branches{stream: false})
+	return nil
+}
+
+func (c *Compiler) OnLeaveZip(depth int, node duct.AstZip) error {
+	b := c.leaveBranches()
+
+	t := node.Pair
+	g := func(x any) (any, error) {
+		pair := reflect.New(t).Elem()
+		for k, f := range b.fs {
+			v, err := f(x)
+			if errors.Is(err, errSkip) {
+				return nil, fmt.Errorf("zip ⟼ %s requires value from branch %d", node.TypeB, k)
+			}
+			if err != nil {
+				return nil, err
+			}
+			pair.Field(k).Set(fn.ValueOf(v, pair.Field(k).Type()))
+		}
+		return pair.Interface(), nil
+	}
+
+	top := c.top()
+	if top.stream {
+		top.ch = c.mapStage(c.par, top.ch, g)
+		return nil
+	}
+
+	top.f, top.t = compose(top.f, g), t
+	return nil
+}
+
+func (c *Compiler) OnEnterFilter(depth int, node duct.AstFilter) error {
+	f, err := fn.Predicate(node.Type, node.F)
+	if err != nil {
+		return err
+	}
+
+	top := c.top()
+	if top.stream {
+		top.ch = c.filterStage(top.ch, f)
+		return nil
+	}
+
+	top.f = compose(top.f, func(x any) (any, error) {
+		ok, err := f(x)
+		switch {
+		case err != nil:
+			return nil, err
+		case !ok:
+			return nil, errSkip
+		default:
+			return x, nil
+		}
+	})
+	return nil
+}
+
+func (c *Compiler) OnLeaveFilter(depth int, node duct.AstFilter) error { return nil }
+
+func (c *Compiler) OnEnterSwitch(depth int, node duct.AstSwitch) error {
+	match, err := matcher(node)
+	if err != nil {
+		return err
+	}
+
+	top := c.top()
+	b := &branches{stream: top.stream}
+	if top.stream {
+		b.in = c.route(top.ch, len(node.Cases), match)
+	}
+	c.fork = append(c.fork, b)
+	return nil
+}
+
+func (c *Compiler) OnLeaveSwitch(depth int, node duct.AstSwitch) error {
+	b := c.leaveBranches()
+
+	top := c.top()
+	if top.stream {
+		top.ch = pipe.Join(c.ctx, b.out...)
+		return nil
+	}
+
+	match, err := matcher(node)
+	if err != nil {
+		return err
+	}
+
+	top.f = compose(top.f, func(x any) (any, error) {
+		k, err := match(x)
+		switch {
+		case err != nil:
+			return nil, err
+		case k < 0:
+			return nil, errSkip
+		default:
+			return b.fs[k](x)
+		}
+	})
+	if len(b.ts) > 0 {
+		top.t = b.ts[0]
+	}
+	return nil
+}
+
+func (c *Compiler) OnEnterFold(depth int, node duct.AstFold) error {
+	empty, combine, t, err := fn.Monoid(node.Type, node.Monoid)
+	if err != nil {
+		return err
+	}
+
+	top := c.top()
+	if top.stream {
+		top.ch = pipe.Fold(c.ctx, top.ch, monoid.FromOp(empty(), combine))
+		return nil
+	}
+
+	c.aggregate(top, t, func(xs []any) ([]any, error) {
+		acc := empty()
+		for _, x := range xs {
+			acc = combine(acc, x)
+		}
+		return []any{acc}, nil
+	})
+	return nil
+}
+
+func (c *Compiler) OnLeaveFold(depth int, node duct.AstFold) error { return nil }
+
+func (c *Compiler) OnEnterBatch(depth int, node duct.AstBatch) error {
+	if node.Size < 1 || node.Slice == nil {
+		return fmt.Errorf("batch of %s requires positive size", node.Type)
+	}
+
+	top := c.top()
+	if top.stream {
+		top.ch = c.batchStage(top.ch, node.Size, node.Slice)
+		return nil
+	}
+
+	c.aggregate(top, node.Slice, func(xs []any) ([]any, error) {
+		ys := make([]any, 0, (len(xs)+node.Size-1)/node.Size)
+		for i := 0; i < len(xs); i += node.Size {
+			ys = append(ys, fn.Batch(node.Slice, xs[i:min(i+node.Size, len(xs))]))
+		}
+		return ys, nil
+	})
+	return nil
+}
+
+func (c *Compiler) OnLeaveBatch(depth int, node duct.AstBatch) error { return nil }
+
+func (c *Compiler) OnEnterGroupBy(depth int, node duct.AstGroupBy) error {
+	key, t, err := fn.Grouping(node.TypeA, node.F)
+	if err != nil {
+		return err
+	}
+
+	top := c.top()
+	if top.stream {
+		top.ch = c.groupStage(top.ch, key, t)
+		return nil
+	}
+
+	c.aggregate(top, t, func(xs []any) ([]any, error) {
+		groups := reflect.MakeMap(t)
+		for _, x := range xs {
+			if err := fn.Group(groups, key, x); err != nil {
+				return nil, err
+			}
+		}
+		return []any{groups.Interface()}, nil
+	})
+	return nil
+}
+
+func (c *Compiler) OnLeaveGroupBy(depth int, node duct.AstGroupBy) error { return nil }
+
+// aggregation of synchronous scope, elements produced by the element-wise
+// function are aggregated, the function is reset.
+func (c *Compiler) aggregate(top *scope, t reflect.Type, g func([]any) ([]any, error)) {
+	agg, f := top.agg, top.f
+	top.agg = func(xs []any) ([]any, error) {
+		if agg != nil {
+			var err error
+			if xs, err = agg(xs); err != nil {
+				return nil, err
+			}
+		}
+
+		ys := make([]any, 0, len(xs))
+		for _, x := range xs {
+			y, err := f(x)
+			if errors.Is(err, errSkip) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			ys = append(ys, y)
+		}
+
+		return g(ys)
+	}
+	top.f, top.t = func(x any) (any, error) { return x, nil }, t
+}
+
+func (c *Compiler) leaveBranches() *branches {
+	b := c.fork[len(c.fork)-1]
+	c.fork = c.fork[:len(c.fork)-1]
+	return b
+}
+
+//------------------------------------------------------------------------------
+
+// broadcast elements of top scope to n branches, the input is drained if n is 0
+func (c *Compiler) broadcast(n int, stream bool) {
+	b := &branches{stream: stream}
+	if stream {
+		b.in = c.route(c.top().ch, n, nil)
+	}
+	c.fork = append(c.fork, b)
+}
+
+// route elements to n channels, each element is sent to the channel chosen by
+// match function or to all channels if match is not defined.
+func (c *Compiler) route(in <-chan any, n int, match func(any) (int, error)) []<-chan any {
+	chs := make([]chan any, n)
+	out := make([]<-chan any, n)
+	for i := range chs {
+		chs[i] = make(chan any, cap(in))
+		out[i] = chs[i]
+	}
+
+	exx := make(chan error, cap(in))
+	c.exx = append(c.exx, exx)
+
+	send := func(ch chan<- any, x any) bool {
+		select {
+		case ch <- x:
+			return true
+		case <-c.ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer func() {
+			for _, ch := range chs {
+				close(ch)
+			}
+			close(exx)
+		}()
+
+		for x := range in {
+			if match == nil {
+				for _, ch := range chs {
+					if !send(ch, x) {
+						return
+					}
+				}
+				continue
+			}
+
+			k, err := match(x)
+			switch {
+			case err != nil:
+				select {
+				case exx <- err:
+				case <-c.ctx.Done():
+					return
+				}
+			case k >= 0:
+				if !send(chs[k], x) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (c *Compiler) filterStage(in <-chan any, f func(any) (bool, error)) <-chan any {
+	var (
+		out <-chan any
+		exx <-chan error
+	)
+
+	if c.par > 1 {
+		out, exx = fork.FilterE(c.ctx, c.par, in, fork.Try(f))
+	} else {
+		out, exx = pipe.FilterE(c.ctx, in, pipe.Try(f))
+	}
+
+	c.exx = append(c.exx, exx)
+	return out
+}
+
+// number of workers of the stage, the node attributes overrides the default
+func (c *Compiler) parallelism(attrs duct.Attributes) int {
+	if attrs.Parallelism > 0 {
+		return attrs.Parallelism
+	}
+	return c.par
+}
+
+func (c *Compiler) mapStage(par int, in <-chan any, f func(any) (any, error)) <-chan any {
+	return c.fmapStage(par, in,
+		func(ctx context.Context, x any, out chan<- any) error {
+			y, err := f(x)
+			switch {
+			case errors.Is(err, errSkip):
+				return nil
+			case err != nil:
+				return err
+			}
+
+			select {
+			case out <- y:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	)
+}
+
+func (c *Compiler) fmapStage(par int, in <-chan any, f func(context.Context, any, chan<- any) error) <-chan any {
+	var (
+		out <-chan any
+		exx <-chan error
+	)
+
+	if par > 1 {
+		out, exx = fork.FMap(c.ctx, par, in, fork.TryF(f))
+	} else {
+		out, exx = pipe.FMap(c.ctx, in, pipe.TryF(f))
+	}
+
+	c.exx = append(c.exx, exx)
+	return out
+}
+
+// batches of elements, the last batch is emitted when input is closed
+func (c *Compiler) batchStage(in <-chan any, size int, t reflect.Type) <-chan any {
+	out := make(chan any, cap(in))
+
+	go func() {
+		defer close(out)
+
+		buf := make([]any, 0, size)
+		emit := func() bool {
+			select {
+			case out <- fn.Batch(t, buf):
+				buf = make([]any, 0, size)
+				return true
+			case <-c.ctx.Done():
+				return false
+			}
+		}
+
+		for x := range in {
+			buf = append(buf, x)
+			if len(buf) == size && !emit() {
+				return
+			}
+		}
+
+		if len(buf) > 0 {
+			emit()
+		}
+	}()
+
+	return out
+}
+
+// groups of elements, emitted as single value when input is closed
+func (c *Compiler) groupStage(in <-chan any, key func(any) (any, error), t reflect.Type) <-chan any {
+	out := make(chan any, 1)
+	exx := make(chan error, cap(in))
+	c.exx = append(c.exx, exx)
+
+	go func() {
+		defer close(out)
+		defer close(exx)
+
+		groups := reflect.MakeMap(t)
+		for x := range in {
+			if err := fn.Group(groups, key, x); err != nil {
+				select {
+				case exx <- err:
+				case <-c.ctx.Done():
+					return
+				}
+			}
+		}
+
+		select {
+		case out <- groups.Interface():
+		case <-c.ctx.Done():
+		}
+	}()
+
+	return out
+}
+
+func (c *Compiler) flatten(in <-chan any) <-chan any {
+	return c.fmapStage(c.par, in,
+		func(ctx context.Context, x any, out chan<- any) error {
+			seq := reflect.ValueOf(x)
+			if seq.Kind() != reflect.Slice && seq.Kind() != reflect.Array {
+				return fmt.Errorf("unable to flatten %T", x)
+			}
+
+			for i := 0; i < seq.Len(); i++ {
+				select {
+				case out <- seq.Index(i).Interface():
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		},
+	)
+}
+
+func (c *Compiler) yieldStage(par int, in <-chan any, f func(any) error) <-chan any {
+	return c.fmapStage(par, in,
+		func(ctx context.Context, x any, out chan<- any) error { return f(x) },
+	)
+}
+
+// source of elements, either channel or slice
+func (c *Compiler) source(node duct.AstFrom) (<-chan any, error) {
+	src := reflect.ValueOf(node.Source)
+
+	switch {
+	case src.Kind() == reflect.Chan && src.Type().ChanDir()&reflect.RecvDir != 0:
+		out := make(chan any, src.Cap())
+		go func() {
+			defer close(out)
+
+			cases := []reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.ctx.Done())},
+				{Dir: reflect.SelectRecv, Chan: src},
+			}
+
+			for {
+				chosen, x, ok := reflect.Select(cases)
+				if chosen == 0 || !ok {
+					return
+				}
+
+				select {
+				case out <- x.Interface():
+				case <-c.ctx.Done():
+					return
+				}
+			}
+		}()
+		return out, nil
+
+	case src.Kind() == reflect.Slice || src.Kind() == reflect.Array:
+		seq := make([]any, src.Len())
+		for i := 0; i < src.Len(); i++ {
+			seq[i] = src.Index(i).Interface()
+		}
+		return pipe.Seq(seq...), nil
+
+	default:
+		return nil, fmt.Errorf("source of %s is not channel or slice: %T", node.Type, node.Source)
+	}
+}
+
+// target of elements, either channel or function
+func (c *Compiler) target(node duct.AstYield) (func(any) error, error) {
+	return c.sink(node.Type, node.Target)
+}
+
+// sink of elements of type t, either channel or function
+func (c *Compiler) sink(t string, target any) (func(any) error, error) {
+	eg := reflect.ValueOf(target)
+
+	switch {
+	case eg.Kind() == reflect.Chan && eg.Type().ChanDir()&reflect.SendDir != 0:
+		t := eg.Type().Elem()
+		return func(x any) error {
+			cases := []reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.ctx.Done())},
+				{Dir: reflect.SelectSend, Chan: eg, Send: fn.ValueOf(x, t)},
+			}
+			if chosen, _, _ := reflect.Select(cases); chosen == 0 {
+				return c.ctx.Err()
+			}
+			return nil
+		}, nil
+
+	case eg.Kind() == reflect.Func && eg.Type().NumIn() == 1 && eg.Type().NumOut() == 1 && eg.Type().Out(0) == fn.ErrorType:
+		in := eg.Type().In(0)
+		return func(x any) error {
+			arg, err := fn.ArgOf(x, in)
+			if err != nil {
+				return fmt.Errorf("target of %s: %w", t, err)
+			}
+
+			out := eg.Call([]reflect.Value{arg})
+			if out[0].IsNil() {
+				return nil
+			}
+			return out[0].Interface().(error)
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("target of %s is not channel or func(%s) error: %T", t, t, target)
+	}
+}
+
+// function of transformer func(A) (B, error) with failure policy of the node
+func (c *Compiler) function(node duct.AstMap) (func(any) (any, error), reflect.Type, error) {
+	policy := fn.Policy{Catch: node.Catch}
+	if node.Retry != nil {
+		policy.Attempts, policy.Backoff = node.Retry.Attempts, node.Retry.Backoff
+	}
+
+	f, t, err := fn.Transformer(c.ctx, node.TypeA, node.TypeB, node.F, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.dlq == nil {
+		return f, t, nil
+	}
+
+	return func(x any) (any, error) {
+		y, err := f(x)
+		if err == nil {
+			return y, nil
+		}
+
+		if err := c.dlq(duct.FailureOf(x, err)); err != nil {
+			return nil, err
+		}
+		return nil, errSkip
+	}, t, nil
+}
+
+// matcher returns index of the first case matching the element, -1 otherwise
+func matcher(node duct.AstSwitch) (func(any) (int, error), error) {
+	preds := make([]func(any) (bool, error), len(node.Cases))
+	for i, c := range node.Cases {
+		if c.F != nil {
+			f, err := fn.Predicate(c.Type, c.F)
+			if err != nil {
+				return nil, err
+			}
+			preds[i] = f
+		}
+	}
+
+	return func(x any) (int, error) {
+		for i, c := range node.Cases {
+			if !node.Match(c, x) {
+				continue
+			}
+
+			if preds[i] != nil {
+				ok, err := preds[i](x)
+				if err != nil {
+					return -1, err
+				}
+				if !ok {
+					continue
+				}
+			}
+
+			return i, nil
+		}
+		return -1, nil
+	}, nil
+}
+
+func compose(f, g func(any) (any, error)) func(any) (any, error) {
+	return func(x any) (any, error) {
+		y, err := f(x)
+		if err != nil {
+			return nil, err
+		}
+		return g(y)
+	}
+}
@@ -0,0 +1,414 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/golem/duct/stream"
+	"github.com/fogfish/golem/pipe/v2"
+	"github.com/fogfish/it/v2"
+)
+
+var (
+	itoa = duct.L2[int, string](func(x int) (string, error) { return strconv.Itoa(x), nil })
+	atoi = duct.L2[string, int](strconv.Atoi)
+	seq  = duct.L2[int, []int](func(x int) ([]int, error) {
+		seq := make([]int, x)
+		for i := 0; i < x; i++ {
+			seq[i] = x
+		}
+		return seq, nil
+	})
+	odd = duct.L2[int, bool](func(x int) (bool, error) { return x%2 == 1, nil })
+)
+
+// monoid of integers under addition
+type sum struct{}
+
+func (sum) Empty() int           { return 0 }
+func (sum) Combine(a, b int) int { return a + b }
+
+type sink[T any] struct {
+	sync.Mutex
+	seq []T
+}
+
+func (s *sink[T]) Put(x T) error {
+	s.Lock()
+	defer s.Unlock()
+	s.seq = append(s.seq, x)
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	for _, par := range []int{1, 4} {
+		t.Run(fmt.Sprintf("Join/%d", par), func(t *testing.T) {
+			m := duct.Join(itoa, duct.From(duct.L1[int](pipe.Seq(1, 2, 3))))
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Seq(vals).Contain().AllOf("1", "2", "3"),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Yield/%d", par), func(t *testing.T) {
+			s := &sink[int]{}
+			m := duct.Yield(duct.L1[int](s.Put),
+				duct.WrapF(
+					duct.Join(seq, duct.From(duct.L1[int]([]int{1, 2, 3}))),
+				),
+			)
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			<-pipe.Void(context.Background(), pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Seq(s.seq).Contain().AllOf(1, 2, 2, 3, 3, 3),
+			)
+		})
+
+		t.Run(fmt.Sprintf("LiftF/%d", par), func(t *testing.T) {
+			eg := make(chan string, 10)
+			m := duct.Yield(duct.L1[string](eg),
+				duct.LiftF(itoa,
+					duct.Join(seq, duct.From(duct.L1[int]([]int{1, 2}))),
+				),
+			)
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			<-pipe.Void(context.Background(), pipe.StdErr(out, exx))
+			close(eg)
+			it.Then(t).Should(
+				it.Seq(pipe.ToSeq(eg)).Contain().AllOf("1", "2", "2"),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Unit/%d", par), func(t *testing.T) {
+			m := duct.Unit(
+				duct.LiftF(itoa,
+					duct.Join(seq, duct.From(duct.L1[int]([]int{2}))),
+				),
+			)
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 1),
+				it.Seq(vals[0]).Equal("2", "2"),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Fork/%d", par), func(t *testing.T) {
+			a, b := &sink[int]{}, &sink[string]{}
+			m := duct.Fork(duct.From(duct.L1[int]([]int{1, 2, 3})),
+				func(m duct.Morphism[int, int]) duct.Morphism[int, duct.Void] {
+					return duct.Yield(duct.L1[int](a.Put), m)
+				},
+				func(m duct.Morphism[int, int]) duct.Morphism[int, duct.Void] {
+					return duct.Yield(duct.L1[string](b.Put), duct.Join(itoa, m))
+				},
+			)
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			<-pipe.Void(context.Background(), pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Seq(a.seq).Contain().AllOf(1, 2, 3),
+				it.Seq(b.seq).Contain().AllOf("1", "2", "3"),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Merge/%d", par), func(t *testing.T) {
+			m := duct.Merge(duct.From(duct.L1[int]([]int{1, 2})),
+				func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) },
+				func(m duct.Morphism[int, int]) duct.Morphism[int, string] {
+					return duct.LiftF(itoa, duct.Join(seq, m))
+				},
+			)
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 5),
+				it.Seq(vals).Contain().AllOf("1", "2"),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Zip/%d", par), func(t *testing.T) {
+			m := duct.Zip(duct.From(duct.L1[int]([]int{1, 2})),
+				func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) },
+				func(m duct.Morphism[int, int]) duct.Morphism[int, []int] { return duct.Join(seq, m) },
+			)
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Seq(vals).Contain().AllOf(
+					duct.Pair[string, []int]{Fst: "1", Snd: []int{1}},
+					duct.Pair[string, []int]{Fst: "2", Snd: []int{2, 2}},
+				),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Filter/%d", par), func(t *testing.T) {
+			m := duct.Filter(odd, duct.From(duct.L1[int]([]int{1, 2, 3, 4})))
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 2),
+				it.Seq(vals).Contain().AllOf(1, 3),
+			)
+		})
+
+		t.Run(fmt.Sprintf("FilterUnit/%d", par), func(t *testing.T) {
+			m := duct.Unit(duct.Filter(odd, duct.WrapF(duct.From(duct.L1[[]int]([][]int{{1, 2, 3}})))))
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 1),
+				it.Seq(vals[0]).Equal(1, 3),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Switch/%d", par), func(t *testing.T) {
+			m := duct.Switch(duct.From(duct.L1[int]([]int{1, 2, 3})),
+				duct.When(odd, func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) }),
+				duct.Otherwise(func(m duct.Morphism[int, int]) duct.Morphism[int, string] {
+					return duct.LiftF(itoa, duct.Join(seq, m))
+				}),
+			)
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 4),
+				it.Seq(vals).Contain().AllOf("1", "2", "3"),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Fold/%d", par), func(t *testing.T) {
+			m := duct.Fold(sum{}, duct.From(duct.L1[int]([]int{1, 2, 3, 4})))
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Seq(vals).Equal(10),
+			)
+		})
+
+		t.Run(fmt.Sprintf("FoldUnit/%d", par), func(t *testing.T) {
+			m := duct.Unit(duct.Fold(sum{}, duct.WrapF(duct.Join(seq, duct.From(duct.L1[int]([]int{2, 3}))))))
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 2),
+				it.Equal(len(vals[0]), 1),
+				it.Equal(len(vals[1]), 1),
+				it.Equal(vals[0][0]+vals[1][0], 13),
+			)
+		})
+
+		t.Run(fmt.Sprintf("Batch/%d", par), func(t *testing.T) {
+			m := duct.Batch(2, duct.From(duct.L1[int]([]int{1, 2, 3, 4, 5})))
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 3),
+				it.Seq(vals[0]).Equal(1, 2),
+				it.Seq(vals[1]).Equal(3, 4),
+				it.Seq(vals[2]).Equal(5),
+			)
+		})
+
+		t.Run(fmt.Sprintf("BatchUnit/%d", par), func(t *testing.T) {
+			m := duct.Unit(duct.Batch(2, duct.Filter(odd, duct.WrapF(duct.Join(seq, duct.From(duct.L1[int]([]int{3})))))))
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 1),
+				it.Equal(len(vals[0]), 2),
+				it.Seq(vals[0][0]).Equal(3, 3),
+				it.Seq(vals[0][1]).Equal(3),
+			)
+		})
+
+		t.Run(fmt.Sprintf("GroupBy/%d", par), func(t *testing.T) {
+			m := duct.GroupBy(odd, duct.From(duct.L1[int]([]int{1, 2, 3, 4, 5})))
+
+			out, exx, err := stream.Run(context.Background(), par, m)
+			it.Then(t).Should(it.Nil(err))
+
+			vals := pipe.ToSeq(pipe.StdErr(out, exx))
+			it.Then(t).Should(
+				it.Equal(len(vals), 1),
+				it.Seq(vals[0][true]).Equal(1, 3, 5),
+				it.Seq(vals[0][false]).Equal(2, 4),
+			)
+		})
+	}
+
+	t.Run("Err", func(t *testing.T) {
+		m := duct.Join(atoi, duct.From(duct.L1[string]([]string{"1", "x", "3"})))
+
+		out, exx, err := stream.Run(context.Background(), 1, m)
+		it.Then(t).Should(it.Nil(err))
+
+		var errs []error
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs = pipe.ToSeq(exx)
+		}()
+
+		vals := pipe.ToSeq(out)
+		wg.Wait()
+
+		it.Then(t).Should(
+			it.Seq(vals).Equal(1, 3),
+			it.Equal(len(errs), 1),
+		)
+	})
+
+	t.Run("InvalidType", func(t *testing.T) {
+		f := duct.L2[string, int](func(x int) (int, error) { return x, nil })
+		m := duct.Join(f, duct.From(duct.L1[string]([]string{"1"})))
+
+		_, _, err := stream.Run(context.Background(), 1, m)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Fail(func() error { return err }).Contain("transformer input int does not match string"),
+		)
+	})
+
+	t.Run("InvalidTarget", func(t *testing.T) {
+		m := duct.Yield(duct.L1[int](func(int) {}), duct.From(duct.L1[int]([]int{1})))
+
+		_, _, err := stream.Run(context.Background(), 1, m)
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("is not channel or func(int) error"),
+		)
+	})
+
+	t.Run("DeadLetter", func(t *testing.T) {
+		dlq := make(chan duct.Failure, 10)
+		m := duct.DeadLetter(duct.L1[duct.Failure](dlq),
+			duct.Join(
+				duct.Retry(duct.RetryPolicy{Attempts: 2, Delay: time.Millisecond}, atoi),
+				duct.From(duct.L1[string]([]string{"1", "x", "3"})),
+			),
+		)
+
+		out, exx, err := stream.Run(context.Background(), 1, m)
+		it.Then(t).Should(it.Nil(err))
+
+		vals := pipe.ToSeq(pipe.StdErr(out, exx))
+		close(dlq)
+		fails := pipe.ToSeq(dlq)
+		it.Then(t).Should(
+			it.Seq(vals).Equal(1, 3),
+			it.Equal(len(fails), 1),
+			it.Equal(fails[0].Value.(string), "x"),
+		)
+	})
+
+	t.Run("Parallelism", func(t *testing.T) {
+		var mu sync.Mutex
+		active, peak := 0, 0
+		f := duct.L2[int, int](func(x int) (int, error) {
+			mu.Lock()
+			active++
+			peak = max(peak, active)
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return x, nil
+		})
+
+		m := duct.Join(f.With(duct.Attributes{Parallelism: 4}),
+			duct.From(duct.L1[int]([]int{1, 2, 3, 4, 5, 6, 7, 8})),
+		)
+
+		out, exx, err := stream.Run(context.Background(), 1, m)
+		it.Then(t).Should(it.Nil(err))
+
+		vals := pipe.ToSeq(pipe.StdErr(out, exx))
+		it.Then(t).Should(
+			it.Seq(vals).Contain().AllOf(1, 2, 3, 4, 5, 6, 7, 8),
+			it.True(peak > 1),
+		)
+	})
+
+	t.Run("AggregationZip", func(t *testing.T) {
+		m := duct.Zip(duct.From(duct.L1[int]([]int{1})),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, int] { return duct.Fold(sum{}, m) },
+			func(m duct.Morphism[int, int]) duct.Morphism[int, int] { return m },
+		)
+
+		_, _, err := stream.Run(context.Background(), 1, m)
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("aggregation is not supported"),
+		)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		m := duct.Join(duct.L2[int, string](strconv.Itoa), duct.From(duct.L1[int]([]int{1})))
+
+		_, _, err := stream.Run(context.Background(), 1, m)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
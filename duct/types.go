@@ -0,0 +1,191 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// package qualified name of the type, it follows Go syntax of type literals
+func typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	case reflect.Chan:
+		return chanName(t)
+	case reflect.Func:
+		return "func" + signatureName(t)
+	case reflect.Struct:
+		return structName(t)
+	case reflect.Interface:
+		return interfaceName(t)
+	default:
+		return t.String()
+	}
+}
+
+func chanName(t reflect.Type) string {
+	elem := typeName(t.Elem())
+
+	switch t.ChanDir() {
+	case reflect.RecvDir:
+		return "<-chan " + elem
+	case reflect.SendDir:
+		return "chan<- " + elem
+	default:
+		// Note: chan (<-chan A) is not same as chan<- (chan A)
+		if t.Elem().Kind() == reflect.Chan && t.Elem().ChanDir() == reflect.RecvDir && t.Elem().Name() == "" {
+			return "chan (" + elem + ")"
+		}
+		return "chan " + elem
+	}
+}
+
+func signatureName(t reflect.Type) string {
+	in := make([]string, t.NumIn())
+	for i := range in {
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in[i] = "..." + typeName(t.In(i).Elem())
+		} else {
+			in[i] = typeName(t.In(i))
+		}
+	}
+
+	out := make([]string, t.NumOut())
+	for i := range out {
+		out[i] = typeName(t.Out(i))
+	}
+
+	switch len(out) {
+	case 0:
+		return "(" + strings.Join(in, ", ") + ")"
+	case 1:
+		return "(" + strings.Join(in, ", ") + ") " + out[0]
+	default:
+		return "(" + strings.Join(in, ", ") + ") (" + strings.Join(out, ", ") + ")"
+	}
+}
+
+func structName(t reflect.Type) string {
+	if t.NumField() == 0 {
+		return "struct {}"
+	}
+
+	fields := make([]string, t.NumField())
+	for i := range fields {
+		f := t.Field(i)
+
+		name := f.Name
+		if !f.IsExported() {
+			name = f.PkgPath + "." + f.Name
+		}
+
+		switch {
+		case f.Anonymous:
+			fields[i] = typeName(f.Type)
+		default:
+			fields[i] = name + " " + typeName(f.Type)
+		}
+
+		if f.Tag != "" {
+			fields[i] += " " + strconv.Quote(string(f.Tag))
+		}
+	}
+
+	return "struct { " + strings.Join(fields, "; ") + " }"
+}
+
+func interfaceName(t reflect.Type) string {
+	if t.NumMethod() == 0 {
+		return "interface {}"
+	}
+
+	methods := make([]string, t.NumMethod())
+	for i := range methods {
+		m := t.Method(i)
+
+		name := m.Name
+		if !m.IsExported() {
+			name = m.PkgPath + "." + m.Name
+		}
+
+		methods[i] = name + signatureName(m.Type)
+	}
+
+	return "interface { " + strings.Join(methods, "; ") + " }"
+}
+
+// ShortType returns short form of the type name for display, package paths
+// are omitted from qualified identifiers (e.g. github.com/fogfish/golem/duct.Void
+// becomes Void). Struct tags are kept as is.
+func ShortType(name string) string {
+	sb := strings.Builder{}
+	id := strings.Builder{}
+
+	flush := func() {
+		// Note: leading dots are ellipsis of variadic arguments
+		s := id.String()
+		ident := strings.TrimLeft(s, ".")
+		sb.WriteString(s[:len(s)-len(ident)])
+		if i := strings.LastIndexByte(ident, '.'); i >= 0 {
+			ident = ident[i+1:]
+		}
+		sb.WriteString(ident)
+		id.Reset()
+	}
+
+	quoted, escaped := false, false
+	for _, r := range name {
+		switch {
+		case quoted:
+			sb.WriteRune(r)
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == '"':
+				quoted = false
+			}
+		case r == '"':
+			flush()
+			sb.WriteRune(r)
+			quoted = true
+		case isPathRune(r):
+			id.WriteRune(r)
+		default:
+			flush()
+			sb.WriteRune(r)
+		}
+	}
+	flush()
+
+	return sb.String()
+}
+
+// rune of package path or identifier
+func isPathRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_./-~", r)
+}
@@ -0,0 +1,415 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NodeError is the validation error of AST node
+type NodeError struct {
+	// Path to node, indexes of node at each level of AST
+	Path []int
+	Node Ast
+	// Issue with the node
+	Issue string
+}
+
+func (err *NodeError) Error() string {
+	seq := make([]string, len(err.Path))
+	for i, x := range err.Path {
+		seq[i] = strconv.Itoa(x)
+	}
+
+	return fmt.Sprintf("invalid node /%s (%s): %s", strings.Join(seq, "/"), signature(err.Node), err.Issue)
+}
+
+// signature of node for display
+func signature(node Ast) string {
+	return ShortType(signatureOf(node))
+}
+
+func signatureOf(node Ast) string {
+	switch n := node.(type) {
+	case *AstFrom:
+		return fmt.Sprintf("ƒ: ø ⟼ %s", n.Type)
+	case *AstMap:
+		return fmt.Sprintf("ƒ: %s ⟼ %s", n.TypeA, n.TypeB)
+	case *AstYield:
+		return fmt.Sprintf("ƒ: %s ⟼ ø", n.Type)
+	case *AstSeq:
+		return "𝑚"
+	case *AstFork:
+		return fmt.Sprintf("ƒ: %s ⟼ (ø, ..., ø)", n.Type)
+	case *AstMerge:
+		return fmt.Sprintf("ƒ: %s ⟼ (%s, ..., %s)", n.TypeA, n.TypeB, n.TypeB)
+	case *AstZip:
+		return fmt.Sprintf("ƒ: %s ⟼ %s", n.TypeA, n.TypeB)
+	case *AstFilter:
+		return fmt.Sprintf("ƒ: %s ⟼ %s", n.Type, n.Type)
+	case *AstSwitch:
+		return fmt.Sprintf("ƒ: %s ⟼ (%s | ... | %s)", n.TypeA, n.TypeB, n.TypeB)
+	case *AstFold:
+		return fmt.Sprintf("ƒ: %s ⟼ %s", n.Type, n.Type)
+	case *AstBatch:
+		return fmt.Sprintf("ƒ: %s ⟼ []%s", n.Type, n.Type)
+	case *AstGroupBy:
+		return fmt.Sprintf("ƒ: %s ⟼ %s", n.TypeA, n.TypeB)
+	default:
+		return fmt.Sprintf("%T", node)
+	}
+}
+
+// Validate statically checks the morphism before execution. It checks that
+//   - morphism starts with the source;
+//   - source is channel, slice or array of elements of category A;
+//   - transformers are functions func(A) (B, error) matching declared types;
+//   - target is channel of B or function func(B) error;
+//   - types of consequent nodes line up, the output matches B;
+//   - free monadic context (LiftF, WrapF) is closed either by Unit or Yield,
+//     unless its elements are joined into the output of merge or switch;
+//   - failure handlers are functions func(error) (B, error), dead letter target
+//     is channel of Failure or function func(Failure) error;
+//   - predicates of filter and switch are functions func(A) (bool, error);
+//   - branches of fork are terminated by Yield, branches of merge, zip and
+//     switch produce declared types;
+//   - monoid of fold is monoid.Monoid of declared type, batch size is positive
+//     and key of group by is function func(A) (K, error).
+//
+// It returns all issues found, each of them is NodeError.
+func Validate[A, B any](m Morphism[A, B]) error {
+	return validate(m, false)
+}
+
+// validates the morphism, the evaluation by Eval (or decoding) allows
+// fragments, open free monadic context and undefined (nil) values, e.g.
+// Eval feeds the input value instead of the source.
+func validate[A, B any](m Morphism[A, B], eval bool) error {
+	t, source := "", true
+	if eval && (len(m.code.Seq) == 0 || !isFrom(m.code.Seq[0])) {
+		t, source = TypeOf[A](), false
+	}
+
+	v := &validator{nils: eval}
+	if t := v.seq(nil, m.code, t, source, eval); t != TypeOf[B]() && !(t == "ø" && TypeOf[B]() == TypeOf[Void]()) {
+		v.fail(nil, m.code, "morphism output %s does not match %s", t, TypeOf[B]())
+	}
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+	// undefined (nil) values are allowed
+	nils bool
+}
+
+func (v *validator) fail(path []int, node Ast, issue string, args ...any) {
+	v.errs = append(v.errs, &NodeError{
+		Path:  append([]int{}, path...),
+		Node:  node,
+		Issue: fmt.Sprintf(issue, args...),
+	})
+}
+
+func (v *validator) undefined(path []int, node Ast, kind string) {
+	if !v.nils {
+		v.fail(path, node, "%s is not defined", kind)
+	}
+}
+
+// validates sequence for the input of type t, returns type of output.
+// The source is required only by the morphism, branches are source-less.
+// The open free monadic context is allowed if its elements are flattened
+// into the output of sequence (e.g. branches of merge).
+func (v *validator) seq(path []int, node *AstSeq, t string, source, open bool) string {
+	if source && (len(node.Seq) == 0 || !isFrom(node.Seq[0])) {
+		v.fail(path, node, "morphism is not started with source, use From")
+	}
+
+	if node.DeadLetter != nil {
+		v.sink(path, node, TypeOf[Failure](), node.DeadLetter, "dead letter")
+	}
+
+	for i, x := range node.Seq {
+		at := append(path, i)
+
+		switch n := x.(type) {
+		case *AstFrom:
+			if i != 0 || !source {
+				v.fail(at, n, "source is allowed only at the beginning of morphism")
+			}
+			v.from(at, n)
+			t = n.Type
+		case *AstMap:
+			if n.TypeA != t {
+				v.fail(at, n, "input type %s does not match %s", n.TypeA, t)
+			}
+			v.fmap(at, n)
+			t = n.TypeB
+		case *AstYield:
+			if n.Type != t {
+				v.fail(at, n, "input type %s does not match %s", n.Type, t)
+			}
+			v.yield(at, n)
+			t = "ø"
+		case *AstSeq:
+			if !strings.HasPrefix(t, "[]") {
+				v.fail(at, n, "type %s is not functor", t)
+			}
+			inner := v.seq(at, n, strings.TrimPrefix(t, "[]"), false, open && n.Deferred)
+
+			switch {
+			case !n.Deferred:
+				t = "[]" + inner
+			case inner != "ø" && !open:
+				v.fail(at, n, "free monadic context ⟼ %s is not closed, use Unit or Yield", inner)
+				t = inner
+			default:
+				t = inner
+			}
+		case *AstFork:
+			if n.Type != t {
+				v.fail(at, n, "input type %s does not match %s", n.Type, t)
+			}
+			for k, b := range n.Branches {
+				if out := v.branch(append(at, k), b, n.Type, false); out != "ø" {
+					v.fail(append(at, k), b, "branch ⟼ %s is not terminated, use Yield", out)
+				}
+			}
+			t = "ø"
+		case *AstMerge:
+			if n.TypeA != t {
+				v.fail(at, n, "input type %s does not match %s", n.TypeA, t)
+			}
+			for k, b := range n.Branches {
+				if out := v.branch(append(at, k), b, n.TypeA, true); out != n.TypeB {
+					v.fail(append(at, k), b, "branch output %s does not match %s", out, n.TypeB)
+				}
+			}
+			t = n.TypeB
+		case *AstZip:
+			if n.TypeA != t {
+				v.fail(at, n, "input type %s does not match %s", n.TypeA, t)
+			}
+			if len(n.Branches) != 2 || n.Pair == nil {
+				v.fail(at, n, "zip requires two branches")
+			} else {
+				for k, b := range n.Branches {
+					out := v.branch(append(at, k), b, n.TypeA, false)
+					if name := typeName(n.Pair.Field(k).Type); out != name {
+						v.fail(append(at, k), b, "branch output %s does not match %s", out, name)
+					}
+				}
+			}
+			t = n.TypeB
+		case *AstFilter:
+			if n.Type != t {
+				v.fail(at, n, "input type %s does not match %s", n.Type, t)
+			}
+			v.predicate(at, n, n.Type, n.F)
+		case *AstSwitch:
+			if n.TypeA != t {
+				v.fail(at, n, "input type %s does not match %s", n.TypeA, t)
+			}
+			for k, c := range n.Cases {
+				if c.F != nil {
+					v.predicate(append(at, k), n, c.Type, c.F)
+				}
+				if out := v.branch(append(at, k), c.Branch, c.Type, true); out != n.TypeB {
+					v.fail(append(at, k), c.Branch, "branch output %s does not match %s", out, n.TypeB)
+				}
+			}
+			t = n.TypeB
+		case *AstFold:
+			if n.Type != t {
+				v.fail(at, n, "input type %s does not match %s", n.Type, t)
+			}
+			v.fold(at, n)
+		case *AstBatch:
+			if n.Type != t {
+				v.fail(at, n, "input type %s does not match %s", n.Type, t)
+			}
+			if n.Size < 1 {
+				v.fail(at, n, "batch size %d is not positive", n.Size)
+			}
+			if n.Slice == nil || typeName(n.Slice) != "[]"+n.Type {
+				v.fail(at, n, "batch type is not []%s", n.Type)
+			}
+			t = "[]" + n.Type
+		case *AstGroupBy:
+			if n.TypeA != t {
+				v.fail(at, n, "input type %s does not match %s", n.TypeA, t)
+			}
+			v.groupBy(at, n)
+			t = n.TypeB
+		default:
+			v.fail(at, n, "unsupported node %T", x)
+		}
+	}
+
+	return t
+}
+
+func (v *validator) branch(path []int, node *AstSeq, t string, open bool) string {
+	if node == nil {
+		v.fail(path, node, "branch is not defined")
+		return t
+	}
+	return v.seq(path, node, t, false, open)
+}
+
+func isFrom(node Ast) bool {
+	_, ok := node.(*AstFrom)
+	return ok
+}
+
+func (v *validator) from(path []int, node *AstFrom) {
+	if node.Source == nil {
+		v.undefined(path, node, "source")
+		return
+	}
+
+	t := reflect.TypeOf(node.Source)
+	switch {
+	case t.Kind() == reflect.Chan && t.ChanDir()&reflect.RecvDir != 0:
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+	default:
+		v.fail(path, node, "source %s is not channel, slice or array", typeName(t))
+		return
+	}
+
+	if name := typeName(t.Elem()); name != node.Type {
+		v.fail(path, node, "source of %s does not match %s", name, node.Type)
+	}
+}
+
+func (v *validator) fmap(path []int, node *AstMap) {
+	if node.F == nil {
+		v.undefined(path, node, "transformer")
+		return
+	}
+
+	t := reflect.TypeOf(node.F)
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 2 || t.Out(1) != typeError {
+		v.fail(path, node, "transformer %s is not func(%s) (%s, error)", typeName(t), node.TypeA, node.TypeB)
+		return
+	}
+
+	if name := typeName(t.In(0)); name != node.TypeA {
+		v.fail(path, node, "transformer input %s does not match %s", name, node.TypeA)
+	}
+
+	if name := typeName(t.Out(0)); name != node.TypeB {
+		v.fail(path, node, "transformer output %s does not match %s", name, node.TypeB)
+	}
+
+	if node.Retry != nil && node.Retry.Attempts < 1 {
+		v.fail(path, node, "retry attempts %d is not positive", node.Retry.Attempts)
+	}
+
+	if node.Catch != nil {
+		h := reflect.TypeOf(node.Catch)
+		if h.Kind() != reflect.Func || h.NumIn() != 1 || h.In(0) != typeError || h.NumOut() != 2 || h.Out(1) != typeError || typeName(h.Out(0)) != node.TypeB {
+			v.fail(path, node, "handler %s is not func(error) (%s, error)", typeName(h), node.TypeB)
+		}
+	}
+}
+
+func (v *validator) predicate(path []int, node Ast, t string, f any) {
+	if f == nil {
+		v.undefined(path, node, "predicate")
+		return
+	}
+
+	ft := reflect.TypeOf(f)
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 2 || ft.Out(0).Kind() != reflect.Bool || ft.Out(1) != typeError {
+		v.fail(path, node, "predicate %s is not func(%s) (bool, error)", typeName(ft), t)
+		return
+	}
+
+	if name := typeName(ft.In(0)); name != t {
+		v.fail(path, node, "predicate input %s does not match %s", name, t)
+	}
+}
+
+func (v *validator) fold(path []int, node *AstFold) {
+	if node.Monoid == nil {
+		v.undefined(path, node, "monoid")
+		return
+	}
+
+	m := reflect.TypeOf(node.Monoid)
+	empty, oke := m.MethodByName("Empty")
+	combine, okc := m.MethodByName("Combine")
+	// Note: method types of reflect.Type include the receiver
+	if !oke || !okc || empty.Type.NumIn() != 1 || empty.Type.NumOut() != 1 || combine.Type.NumIn() != 3 || combine.Type.NumOut() != 1 {
+		v.fail(path, node, "monoid %s is not monoid.Monoid[%s]", typeName(m), node.Type)
+		return
+	}
+
+	t := empty.Type.Out(0)
+	if combine.Type.In(1) != t || combine.Type.In(2) != t || combine.Type.Out(0) != t {
+		v.fail(path, node, "monoid %s is not monoid.Monoid[%s]", typeName(m), node.Type)
+		return
+	}
+
+	if name := typeName(t); name != node.Type {
+		v.fail(path, node, "monoid of %s does not match %s", name, node.Type)
+	}
+}
+
+func (v *validator) groupBy(path []int, node *AstGroupBy) {
+	if node.F == nil {
+		v.undefined(path, node, "key")
+		return
+	}
+
+	t := reflect.TypeOf(node.F)
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 2 || t.Out(1) != typeError || !t.Out(0).Comparable() {
+		v.fail(path, node, "key %s is not func(%s) (K, error)", typeName(t), node.TypeA)
+		return
+	}
+
+	if name := typeName(t.In(0)); name != node.TypeA {
+		v.fail(path, node, "key input %s does not match %s", name, node.TypeA)
+	}
+
+	if name := typeName(reflect.MapOf(t.Out(0), reflect.SliceOf(t.In(0)))); name != node.TypeB {
+		v.fail(path, node, "groups %s does not match %s", name, node.TypeB)
+	}
+}
+
+func (v *validator) yield(path []int, node *AstYield) {
+	if node.Target == nil {
+		v.undefined(path, node, "target")
+		return
+	}
+
+	v.sink(path, node, node.Type, node.Target, "target")
+}
+
+func (v *validator) sink(path []int, node Ast, typ string, target any, kind string) {
+	t := reflect.TypeOf(target)
+	switch {
+	case t.Kind() == reflect.Chan && t.ChanDir()&reflect.SendDir != 0:
+		if name := typeName(t.Elem()); name != typ {
+			v.fail(path, node, "%s of %s does not match %s", kind, name, typ)
+		}
+	case t.Kind() == reflect.Func && t.NumIn() == 1 && t.NumOut() == 1 && t.Out(0) == typeError:
+		if name := typeName(t.In(0)); name != typ {
+			v.fail(path, node, "%s of %s does not match %s", kind, name, typ)
+		}
+	default:
+		v.fail(path, node, "%s %s is not channel or func(%s) error", kind, typeName(t), typ)
+	}
+}
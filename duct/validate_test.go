@@ -0,0 +1,201 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package duct_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/fogfish/golem/duct"
+	"github.com/fogfish/it/v2"
+)
+
+func TestValidate(t *testing.T) {
+	var (
+		ints = duct.L1[int](make(chan int))
+		strs = duct.L1[string](func(string) error { return nil })
+	)
+
+	t.Run("Valid", func(t *testing.T) {
+		m := duct.Yield(strs, duct.LiftF(itoa, duct.Join(dup, duct.From(ints))))
+
+		it.Then(t).Should(
+			it.Nil(duct.Validate(m)),
+		)
+	})
+
+	t.Run("ValidUnit", func(t *testing.T) {
+		m := duct.Unit(duct.LiftF(itoa, duct.Join(dup, duct.From(ints))))
+
+		it.Then(t).Should(
+			it.Nil(duct.Validate(m)),
+		)
+	})
+
+	t.Run("Function", func(t *testing.T) {
+		m := duct.Join(duct.L2[int, string](strconv.Itoa), duct.From(ints))
+		err := duct.Validate(m)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Seq(e.Path).Equal(1),
+			it.Fail(func() error { return err }).Contain("is not func(int) (string, error)"),
+		)
+	})
+
+	t.Run("FunctionType", func(t *testing.T) {
+		m := duct.Join(duct.L2[int, string](strconv.Atoi), duct.From(ints))
+		err := duct.Validate(m)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("transformer input string does not match int"),
+			it.Fail(func() error { return err }).Contain("transformer output int does not match string"),
+		)
+	})
+
+	t.Run("Source", func(t *testing.T) {
+		m := duct.From(duct.L1[int](make(chan string)))
+		err := duct.Validate(m)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("source of string does not match int"),
+		)
+	})
+
+	t.Run("Target", func(t *testing.T) {
+		m := duct.Yield(duct.L1[int](func(string) error { return nil }), duct.From(ints))
+		err := duct.Validate(m)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("target of string does not match int"),
+		)
+	})
+
+	t.Run("Undefined", func(t *testing.T) {
+		m := duct.Yield(duct.L1[string](nil), duct.Join(duct.L2[int, string](nil), duct.From(src)))
+		err := duct.Validate(m)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("source is not defined"),
+			it.Fail(func() error { return err }).Contain("transformer is not defined"),
+			it.Fail(func() error { return err }).Contain("target is not defined"),
+		)
+	})
+
+	t.Run("ValidUnitRoot", func(t *testing.T) {
+		m := duct.Unit(duct.Join(itoa, duct.From(ints)))
+
+		it.Then(t).Should(
+			it.Nil(duct.Validate(m)),
+		)
+	})
+
+	t.Run("NotClosed", func(t *testing.T) {
+		m := duct.LiftF(itoa, duct.Join(dup, duct.From(ints)))
+		err := duct.Validate(m)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Seq(e.Path).Equal(2),
+			it.Fail(func() error { return err }).Contain("free monadic context ⟼ string is not closed"),
+		)
+	})
+
+	t.Run("ValidFork", func(t *testing.T) {
+		m := duct.Fork(duct.Join(itoa, duct.From(ints)),
+			func(m duct.Morphism[string, string]) duct.Morphism[string, duct.Void] { return duct.Yield(strs, m) },
+		)
+
+		it.Then(t).Should(
+			it.Nil(duct.Validate(m)),
+		)
+	})
+
+	t.Run("ValidMerge", func(t *testing.T) {
+		m := duct.Merge(duct.From(ints),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) },
+			func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.LiftF(itoa, duct.Join(dup, m)) },
+		)
+
+		it.Then(t).Should(
+			it.Nil(duct.Validate(m)),
+		)
+	})
+
+	t.Run("ValidZip", func(t *testing.T) {
+		m := duct.Zip(duct.From(ints),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) },
+			func(m duct.Morphism[int, int]) duct.Morphism[int, int] { return m },
+		)
+
+		it.Then(t).Should(
+			it.Nil(duct.Validate(m)),
+		)
+	})
+
+	t.Run("ForkNotTerminated", func(t *testing.T) {
+		m := duct.Fork(duct.From(ints),
+			func(m duct.Morphism[int, int]) duct.Morphism[int, duct.Void] {
+				return duct.Morphism[int, duct.Void](duct.LiftF(itoa, duct.Join(dup, m)))
+			},
+		)
+		err := duct.Validate(m)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Seq(e.Path).Equal(1, 0, 1),
+			it.Fail(func() error { return err }).Contain("free monadic context ⟼ string is not closed"),
+			it.Fail(func() error { return err }).Contain("branch ⟼ string is not terminated"),
+		)
+	})
+
+	t.Run("ValidSwitch", func(t *testing.T) {
+		m := duct.Switch(duct.Filter(odd, duct.From(ints)),
+			duct.When(odd, func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) }),
+			duct.Otherwise(func(m duct.Morphism[int, int]) duct.Morphism[int, string] { return duct.Join(itoa, m) }),
+		)
+
+		it.Then(t).Should(
+			it.Nil(duct.Validate(m)),
+		)
+	})
+
+	t.Run("Predicate", func(t *testing.T) {
+		m := duct.Filter(duct.L2[int, bool](func(x int) bool { return true }), duct.From(ints))
+		err := duct.Validate(m)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Seq(e.Path).Equal(1),
+			it.Fail(func() error { return err }).Contain("is not func(int) (bool, error)"),
+		)
+	})
+
+	t.Run("Aggregation", func(t *testing.T) {
+		valid := duct.Unit(duct.Batch(2, duct.GroupBy(odd, duct.Fold(sum{}, duct.WrapF(duct.Join(upto, duct.From(ints)))))))
+		it.Then(t).Should(
+			it.Nil(duct.Validate(valid)),
+		)
+
+		m := duct.Unit(duct.Batch(0, duct.WrapF(duct.Join(upto, duct.From(ints)))))
+		err := duct.Validate(m)
+
+		var e *duct.NodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Seq(e.Path).Equal(2, 0),
+			it.Fail(func() error { return err }).Contain("batch size 0 is not positive"),
+		)
+	})
+}
@@ -19,9 +19,25 @@ import (
 type Type[T any] struct {
 	reflect.StructField
 
+	// Offset of the struct, which declares the field, from the root type
+	// or from the last embedded pointer, see Indirect. The field address is
+	// Offset + RootOffs only if Indirect is empty. Since hseq/v1.4.0, RootOffs
+	// of fields promoted through embedded pointer is relative to the pointed
+	// struct (it resets to 0), earlier it was the offset of the pointer.
 	RootOffs uintptr
 	PureType reflect.Type
 	ID       int
+	// Embedded pointers on the path from root type to the field. The field
+	// is reachable only if none of them is nil.
+	Indirect []Indirect
+}
+
+// Indirect is the pointer to embedded struct
+type Indirect struct {
+	// Offset of the pointer from the root type or from the previous pointer
+	Offset uintptr
+	// Type of struct pointed by the pointer
+	Type reflect.Type
 }
 
 // Unique identity of key associated with type
@@ -44,7 +60,7 @@ func New[T any](names ...string) Seq[T] {
 	}
 
 	seq := make(Seq[T], 0)
-	seq = unfold(cat, seq, 0)
+	seq = unfold(cat, seq, 0, nil)
 
 	if len(names) == 0 {
 		return seq
@@ -58,7 +74,7 @@ func New[T any](names ...string) Seq[T] {
 	return nseq
 }
 
-func unfold[T any](cat reflect.Type, seq Seq[T], offset uintptr) Seq[T] {
+func unfold[T any](cat reflect.Type, seq Seq[T], offset uintptr, indirect []Indirect) Seq[T] {
 	for i := 0; i < cat.NumField(); i++ {
 		fv := cat.Field(i)
 		ft := cat.Field(i).Type
@@ -66,21 +82,22 @@ func unfold[T any](cat reflect.Type, seq Seq[T], offset uintptr) Seq[T] {
 			ft = ft.Elem()
 		}
 
+		seq = append(seq, Type[T]{
+			StructField: fv,
+			RootOffs:    offset,
+			PureType:    ft,
+			ID:          len(seq),
+			Indirect:    indirect,
+		})
+
 		if fv.Anonymous && ft.Kind() == reflect.Struct {
-			seq = append(seq, Type[T]{
-				StructField: fv,
-				RootOffs:    offset,
-				PureType:    ft,
-				ID:          len(seq),
-			})
-			seq = unfold(ft, seq, offset+fv.Offset)
-		} else {
-			seq = append(seq, Type[T]{
-				StructField: fv,
-				RootOffs:    offset,
-				PureType:    ft,
-				ID:          len(seq),
-			})
+			if fv.Type.Kind() == reflect.Ptr {
+				// fields of embedded pointer are relative to the pointed struct
+				ptr := Indirect{Offset: offset + fv.Offset, Type: ft}
+				seq = unfold(ft, seq, 0, append(append([]Indirect{}, indirect...), ptr))
+			} else {
+				seq = unfold(ft, seq, offset+fv.Offset, indirect)
+			}
 		}
 	}
 
@@ -195,12 +212,15 @@ func New9[T, A, B, C, D, E, F, G, H, I any]() Seq[T] {
 	}
 }
 
-type errType struct {
+// Error is the failure of type lookup, it explains the issue and the fix.
+// Lookup functions panic with Error, the type is exported for modules built
+// on top of hseq to report their own failures consistently.
+type Error struct {
 	Issue string
 	FixIt string
 }
 
-func (err errType) Error() string {
+func (err Error) Error() string {
 	return fmt.Sprintf("\n==> Critical Error:\n❌ %s\n\n🛠️ %s\n",
 		err.Issue, err.FixIt)
 }
@@ -236,13 +256,13 @@ func ForType[A, T any](seq Seq[T]) Type[T] {
 	}
 
 	if count > 1 {
-		panic(errType{
+		panic(Error{
 			Issue: fmt.Sprintf("type `%s` is not unique in struct `%s`", innerT, outerT),
 			FixIt: fmt.Sprintf("How To Fix:\n- define unique named type `type A %s` for the field, `type %s struct { A }`.\n- use hseq.ForName instead. \n\n", innerT, outerT),
 		})
 	}
 
-	panic(errType{
+	panic(Error{
 		Issue: fmt.Sprintf("type `%s` is not field of a struct `%s`", innerT, outerT),
 		FixIt: fmt.Sprintf("How To Fix:\n- check for typos in type names.\n- add or embed `%s` as a field to struct `%s`\n\n `type %s struct { %s }`\n `type %s struct { x %s }`\n\n", innerT, outerT, outerT, innerT, outerT, innerT),
 	})
@@ -261,7 +281,7 @@ func ForName[T any](seq Seq[T], field string) Type[T] {
 	if cat.Kind() == reflect.Pointer {
 		outerT = cat.Elem().Name()
 	}
-	err := errType{
+	err := Error{
 		Issue: fmt.Sprintf("field `%s` is not member of a struct `%s`", field, outerT),
 		FixIt: fmt.Sprintf("How To Fix:\n- check for typos in field name.\n- add a field `%s` to struct `%s`\n\n `type %s struct { %s any }`\n\n", field, outerT, outerT, field),
 	}
@@ -269,6 +289,60 @@ func ForName[T any](seq Seq[T], field string) Type[T] {
 	panic(err)
 }
 
+// Lookup type of nested structs by path of member names. The first name is
+// looked up in the sequence, each following one in the type of previous
+// member, which is either struct or pointer to struct. Pointers on the path
+// are described by Indirect, the member is reachable only if none of them
+// is nil.
+//
+//	hseq.ForPath(hseq.New[User](), "Address", "Geo", "Lat")
+func ForPath[T any](seq Seq[T], path ...string) Type[T] {
+	if len(path) == 0 {
+		panic(Error{
+			Issue: "path is empty",
+			FixIt: "How To Fix:\n- use at least one member name `hseq.ForPath(seq, \"A\")`.\n\n",
+		})
+	}
+
+	f := ForName(seq, path[0])
+	for i, field := range path[1:] {
+		at := strings.Join(path[:i+1], ".")
+		if f.PureType.Kind() != reflect.Struct {
+			panic(Error{
+				Issue: fmt.Sprintf("type `%s` of `%s` is not a struct, member `%s` is not accessible", f.Type, at, field),
+				FixIt: "How To Fix:\n- check the path, members are accessible only in structs or pointers to structs.\n\n",
+			})
+		}
+
+		offset, indirect := f.RootOffs+f.Offset, f.Indirect
+		if f.Type.Kind() == reflect.Pointer {
+			ptr := Indirect{Offset: offset, Type: f.PureType}
+			offset, indirect = 0, append(append([]Indirect{}, indirect...), ptr)
+		}
+
+		x, has := ForNameMaybe(unfold(f.PureType, Seq[T]{}, offset, indirect), field)
+		if !has {
+			panic(Error{
+				Issue: fmt.Sprintf("member `%s` of `%s` is not member of a struct `%s`", field, at, f.PureType.Name()),
+				FixIt: fmt.Sprintf("How To Fix:\n- check for typos in member name.\n- add a field `%s` to struct `%s`\n\n `type %s struct { %s any }`\n\n", field, f.PureType.Name(), f.PureType.Name(), field),
+			})
+		}
+		f = x
+	}
+
+	return f
+}
+
+// Unfold type to heterogenous sequence at runtime. It is used by algorithms
+// over types, which are not known at compile time (e.g. elements of maps),
+// the type witness T is opaque for them.
+func Unfold[T any](cat reflect.Type) Seq[T] {
+	if cat.Kind() == reflect.Pointer {
+		cat = cat.Elem()
+	}
+	return unfold(cat, Seq[T]{}, 0, nil)
+}
+
 // Lookup type in heterogenous sequence by name of member
 func ForNameMaybe[T any](seq Seq[T], field string) (Type[T], bool) {
 	for _, f := range seq {
@@ -191,6 +191,33 @@ func TestForName(t *testing.T) {
 		)
 	})
 
+	t.Run("EmbeddedPointer", func(t *testing.T) {
+		type C struct{ S string }
+		type B struct {
+			I int
+			*C
+		}
+		type T struct {
+			A string
+			*B
+		}
+
+		seq := hseq.New[T]()
+		b := hseq.ForName[T](seq, "B")
+		i := hseq.ForName[T](seq, "I")
+		s := hseq.ForName[T](seq, "S")
+
+		it.Then(t).Should(
+			it.Equal(len(b.Indirect), 0),
+			it.Equal(len(i.Indirect), 1),
+			it.Equal(i.Indirect[0].Offset, b.Offset),
+			it.Equal(i.Indirect[0].Type, reflect.TypeOf(B{})),
+			it.Equal(i.RootOffs, 0),
+			it.Equal(len(s.Indirect), 2),
+			it.Equal(s.Indirect[1].Type, reflect.TypeOf(C{})),
+		)
+	})
+
 	t.Run("Unknown", func(t *testing.T) {
 		type A string
 		type T struct{ A }
@@ -205,6 +232,72 @@ func TestForName(t *testing.T) {
 
 }
 
+func TestForPath(t *testing.T) {
+	type G struct{ Lat, Lng float64 }
+	type A struct {
+		City string
+		Geo  *G
+	}
+	type T struct {
+		Name    string
+		Address A
+	}
+
+	seq := hseq.New[T]()
+
+	t.Run("Nested", func(t *testing.T) {
+		a := hseq.ForName(seq, "Address")
+		c := hseq.ForPath(seq, "Address", "City")
+
+		it.Then(t).Should(
+			it.Equal(c.Name, "City"),
+			it.Equal(c.RootOffs, a.Offset),
+			it.Equal(len(c.Indirect), 0),
+		)
+	})
+
+	t.Run("Pointer", func(t *testing.T) {
+		a := hseq.ForName(seq, "Address")
+		g := hseq.ForPath(seq, "Address", "Geo")
+		l := hseq.ForPath(seq, "Address", "Geo", "Lng")
+
+		it.Then(t).Should(
+			it.Equal(l.Name, "Lng"),
+			it.Equal(l.RootOffs, 0),
+			it.Equal(len(l.Indirect), 1),
+			it.Equal(l.Indirect[0].Offset, a.Offset+g.Offset),
+			it.Equal(l.Indirect[0].Type, reflect.TypeOf(G{})),
+		)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Fail(
+				func() { hseq.ForPath(seq) },
+			).Contain("path is empty"),
+			it.Fail(
+				func() { hseq.ForPath(seq, "Address", "Town") },
+			).Contain("is not member of a struct"),
+			it.Fail(
+				func() { hseq.ForPath(seq, "Name", "City") },
+			).Contain("is not a struct"),
+		)
+	})
+}
+
+func TestUnfold(t *testing.T) {
+	type T struct {
+		A string
+		B int
+	}
+
+	seq := hseq.Unfold[any](reflect.TypeOf(&T{}))
+	it.Then(t).Should(
+		it.Equal(len(seq), 2),
+		it.Equal(hseq.ForName(seq, "B").Offset, hseq.ForName(hseq.New[T](), "B").Offset),
+	)
+}
+
 //
 // New
 //
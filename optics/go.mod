@@ -3,6 +3,9 @@ module github.com/fogfish/golem/optics
 go 1.22
 
 require (
-	github.com/fogfish/golem/hseq v1.3.1
+	github.com/fogfish/golem/hseq v1.4.0
+	github.com/fogfish/golem/pure v0.10.1
 	github.com/fogfish/it/v2 v2.0.2
 )
+
+replace github.com/fogfish/golem/hseq => ../hseq
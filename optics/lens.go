@@ -26,15 +26,36 @@ type Lens[S, A any] interface {
 	Put(*S, A) *S
 }
 
-// NewLens instantiates a typed Lens[S, A] for hseq.Type[S]
+// Nil is the policy of lens over the field, which is unreachable because
+// some pointer on the path to it is nil (e.g. nil root or nil embedded pointer).
+type Nil int
+
+const (
+	// Get returns zero value, Put allocates nil pointers on the path
+	NilAlloc Nil = iota
+	// Get returns zero value, Put does nothing
+	NilSkip
+)
+
+// NewLens instantiates a typed Lens[S, A] for hseq.Type[S], nil pointers
+// on the path to the field are allocated by Put, see NilAlloc.
 func NewLens[S, A any](t hseq.Type[S]) Lens[S, A] {
+	return NewLensNil[S, A](t, NilAlloc)
+}
+
+// NewLensNil instantiates a typed Lens[S, A] for hseq.Type[S] using the
+// policy over nil pointers on the path to the field.
+func NewLensNil[S, A any](t hseq.Type[S], nils Nil) Lens[S, A] {
 	ft := t.Type
 	fv := reflect.TypeOf(new(A)).Elem()
 
 	if ft.String() == fv.String() && ft.AssignableTo(fv) {
 		cat := reflect.TypeOf(new(S)).Elem()
 		if cat.Kind() == reflect.Pointer {
-			return &lensP[S, A]{t}
+			return &lensP[S, A]{Type: t, root: cat.Elem(), nils: nils}
+		}
+		if len(t.Indirect) != 0 {
+			return &lensP[S, A]{Type: t, nils: nils}
 		}
 		return &lens[S, A]{t}
 	}
@@ -54,18 +75,67 @@ func (lens *lens[S, A]) Get(s *S) A {
 	return *(*A)(unsafe.Pointer(uintptr(unsafe.Pointer(s)) + lens.Offset + lens.RootOffs))
 }
 
-// lensP handles the case where S is already a pointer type.
+func (lens *lens[S, A]) lookup(s *S) (A, bool) { return lens.Get(s), true }
+
+// lensP handles the case where the field is reachable through pointers:
+// S is already a pointer type or the field belongs to embedded pointer.
 // When S = *T, the interface methods receive **T, so we must dereference
 // to reach the underlying struct before applying field offsets.
-type lensP[S, A any] struct{ hseq.Type[S] }
+type lensP[S, A any] struct {
+	hseq.Type[S]
+	root reflect.Type // type of struct pointed by S, nil if S is not a pointer
+	nils Nil
+}
+
+// address of the field, nil if the field is unreachable
+func (lens *lensP[S, A]) addr(s *S, alloc bool) unsafe.Pointer {
+	return addrOf(unsafe.Pointer(s), lens.root, lens.Indirect, lens.Offset+lens.RootOffs, alloc)
+}
+
+// address of the field at offset from the struct p, pointers on the path
+// (root and indirect) are dereferenced, nil if the field is unreachable
+func addrOf(p unsafe.Pointer, root reflect.Type, indirect []hseq.Indirect, offset uintptr, alloc bool) unsafe.Pointer {
+	if root != nil {
+		if p = deref(p, root, alloc); p == nil {
+			return nil
+		}
+	}
+
+	for _, ptr := range indirect {
+		if p = deref(unsafe.Add(p, ptr.Offset), ptr.Type, alloc); p == nil {
+			return nil
+		}
+	}
+
+	return unsafe.Add(p, offset)
+}
+
+// dereference pointer, allocates the struct of type t if pointer is nil
+func deref(p unsafe.Pointer, t reflect.Type, alloc bool) unsafe.Pointer {
+	ptr := (*unsafe.Pointer)(p)
+	if *ptr == nil && alloc {
+		*ptr = reflect.New(t).UnsafePointer()
+	}
+	return *ptr
+}
 
 func (lens *lensP[S, A]) Put(s *S, a A) *S {
-	*(*A)(unsafe.Pointer(uintptr(*(*unsafe.Pointer)(unsafe.Pointer(s))) + lens.Offset + lens.RootOffs)) = a
+	if p := lens.addr(s, lens.nils == NilAlloc); p != nil {
+		*(*A)(p) = a
+	}
 	return s
 }
 
 func (lens *lensP[S, A]) Get(s *S) A {
-	return *(*A)(unsafe.Pointer(uintptr(*(*unsafe.Pointer)(unsafe.Pointer(s))) + lens.Offset + lens.RootOffs))
+	a, _ := lens.lookup(s)
+	return a
+}
+
+func (lens *lensP[S, A]) lookup(s *S) (A, bool) {
+	if p := lens.addr(s, false); p != nil {
+		return *(*A)(p), true
+	}
+	return *new(A), false
 }
 
 // NewLens instantiates a typed Lens[S, A] for map[K]A
@@ -84,6 +154,13 @@ func (lens *lensM[S, K, A]) Get(s *S) A {
 	return (*s)[lens.key]
 }
 
+func (lens *lensM[S, K, A]) lookup(s *S) (A, bool) {
+	a, has := (*s)[lens.key]
+	return a, has
+}
+
+// Join composes Lens[S, A] with Lens[A, B]. Nil pointers on the path to
+// the focus are handled by the policy of lenses, see Nil.
 func Join[S, A, B any](a Lens[S, A], b Lens[A, B]) Lens[S, B] {
 	return join[S, A, B]{a, b}
 }
@@ -105,6 +182,22 @@ func (lens join[S, A, B]) Get(s *S) B {
 	return lens.b.Get(&va)
 }
 
+func (lens join[S, A, B]) lookup(s *S) (B, bool) {
+	va, has := lookup(lens.a, s)
+	if !has {
+		return *new(B), false
+	}
+	return lookup(lens.b, &va)
+}
+
+// lookup the focus of lens, it does not exist if it is unreachable
+func lookup[S, A any](lens Lens[S, A], s *S) (A, bool) {
+	if l, ok := lens.(interface{ lookup(*S) (A, bool) }); ok {
+		return l.lookup(s)
+	}
+	return lens.Get(s), true
+}
+
 // ForProduct1 unfold 1 attribute of type T
 func ForProduct1[T, A any](attr ...string) Lens[T, A] {
 	var seq hseq.Seq[T]
@@ -14,6 +14,7 @@ import (
 	"io"
 	"testing"
 
+	"github.com/fogfish/golem/hseq"
 	"github.com/fogfish/golem/optics"
 	"github.com/fogfish/it/v2"
 )
@@ -147,7 +148,6 @@ func TestLensLaws(t *testing.T) {
 		type T struct {
 			A
 			B
-			// Note: lens package does not support embedded pointers
 		}
 
 		ls := optics.ForProduct1[T, S]()
@@ -206,6 +206,83 @@ func TestLensLaws(t *testing.T) {
 	})
 }
 
+func TestLensNil(t *testing.T) {
+	type Geo struct{ Lat, Lng float64 }
+	type Address struct {
+		City string
+		*Geo
+	}
+	type T struct {
+		Name string
+		*Address
+	}
+
+	t.Run("PointerType", func(t *testing.T) {
+		type T struct{ A string }
+		seq := hseq.New[*T]()
+		alloc := optics.NewLensNil[*T, string](hseq.ForName(seq, "A"), optics.NilAlloc)
+		skip := optics.NewLensNil[*T, string](hseq.ForName(seq, "A"), optics.NilSkip)
+
+		var tt *T
+		it.Then(t).Should(
+			it.Equal(alloc.Get(&tt), ""),
+			it.Equal(skip.Get(&tt), ""),
+		)
+
+		skip.Put(&tt, "string")
+		it.Then(t).Should(it.True(tt == nil))
+
+		alloc.Put(&tt, "string")
+		it.Then(t).Should(
+			it.Equiv(tt, &T{A: "string"}),
+			it.Equal(skip.Get(&tt), "string"),
+		)
+	})
+
+	t.Run("EmbeddedPointer", func(t *testing.T) {
+		seq := hseq.New[T]()
+		alloc := optics.NewLensNil[T, float64](hseq.ForName(seq, "Lat"), optics.NilAlloc)
+		skip := optics.NewLensNil[T, float64](hseq.ForName(seq, "Lng"), optics.NilSkip)
+		city := optics.ForProduct1[T, string]("City")
+
+		tt := T{Name: "name"}
+		it.Then(t).Should(
+			it.Equal(alloc.Get(&tt), 0.0),
+			it.Equal(skip.Get(&tt), 0.0),
+			it.Equal(city.Get(&tt), ""),
+		)
+
+		skip.Put(&tt, 1.0)
+		it.Then(t).Should(it.True(tt.Address == nil))
+
+		alloc.Put(&tt, 1.0)
+		skip.Put(&tt, 2.0)
+		city.Put(&tt, "city")
+		it.Then(t).Should(
+			it.Equiv(tt, T{Name: "name", Address: &Address{City: "city", Geo: &Geo{Lat: 1.0, Lng: 2.0}}}),
+			it.Equal(alloc.Get(&tt), 1.0),
+			it.Equal(skip.Get(&tt), 2.0),
+		)
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		type Outer struct{ Address *Address }
+		addr := optics.ForProduct1[Outer, *Address]()
+		lat := optics.Join(addr, optics.ForProduct1[*Address, float64]("Lat"))
+
+		tt := Outer{}
+		it.Then(t).Should(
+			it.Equal(lat.Get(&tt), 0.0),
+		)
+
+		lat.Put(&tt, 1.0)
+		it.Then(t).Should(
+			it.Equiv(tt, Outer{Address: &Address{Geo: &Geo{Lat: 1.0}}}),
+			it.Equal(lat.Get(&tt), 1.0),
+		)
+	})
+}
+
 //
 //
 //
@@ -0,0 +1,367 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package optics
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/fogfish/golem/hseq"
+)
+
+// ForPath builds Lens[T, A] focused on the field addressed by the path
+// expression. Field names are separated by dot, map keys are given in
+// brackets, strings are quoted:
+//
+//	optics.ForPath[User, float64]("Address.Geo.Lat")
+//	optics.ForPath[User, string](`Tags["env"]`)
+//
+// Fields of nested structs are looked up by hseq.ForPath (promoted fields,
+// `hseq` tag), the path without map keys is the lens NewLens. Map keys are
+// composed with fields by Join. Nil pointers and maps on the path are
+// allocated by Put, Get returns zero value, see NilAlloc. The path is
+// validated at construction, it panics with hseq.Error if the path is
+// invalid. Compiled paths are cached.
+func ForPath[T, A any](path string) Lens[T, A] {
+	return ForPathNil[T, A](path, NilAlloc)
+}
+
+// ForPathNil builds Lens[T, A] focused on the field addressed by the path
+// expression using the policy over nil pointers and maps on the path.
+// See ForPath for details.
+func ForPathNil[T, A any](path string, nils Nil) Lens[T, A] {
+	key := pathKey{
+		Type:  reflect.TypeOf(new(T)).Elem(),
+		Focus: reflect.TypeOf(new(A)).Elem(),
+		Path:  path,
+		Nils:  nils,
+	}
+	if lens, has := paths.Load(key); has {
+		return lens.(Lens[T, A])
+	}
+
+	lens := compilePath[T, A](path, nils)
+	paths.Store(key, lens)
+	return lens
+}
+
+type pathKey struct {
+	Type  reflect.Type
+	Focus reflect.Type
+	Path  string
+	Nils  Nil
+}
+
+// cache of compiled paths
+var paths sync.Map
+
+func compilePath[T, A any](path string, nils Nil) Lens[T, A] {
+	seq, ok := parsePath(path)
+	if !ok {
+		panic(hseq.Error{
+			Issue: fmt.Sprintf("path `%s` is not valid", path),
+			FixIt: "How To Fix:\n- separate field names with dot `A.B.C`.\n- use brackets for map keys, quote strings `A[\"key\"].B`.\n\n",
+		})
+	}
+
+	names, tail := fieldsOf(seq)
+	t := hseq.ForPath(hseq.New[T](), names...)
+	if len(tail) == 0 {
+		return NewLensNil[T, A](t, nils)
+	}
+
+	var (
+		lens Lens[T, any] = &lensF[T]{newField(reflect.TypeOf(new(T)).Elem(), t.Type, t.Indirect, t.Offset+t.RootOffs, nils)}
+		at                = t.Type
+	)
+	for len(tail) > 0 {
+		var k Lens[any, any]
+		k, at = compileKey(at, tail[0].name, path, seq[:len(seq)-len(tail)], nils)
+		lens = Join(lens, k)
+
+		names, tail = fieldsOf(tail[1:])
+		if len(names) > 0 {
+			if st := at; st.Kind() != reflect.Struct && (st.Kind() != reflect.Pointer || st.Elem().Kind() != reflect.Struct) {
+				panic(hseq.Error{
+					Issue: fmt.Sprintf("type `%s` at `%s` of path `%s` is not a struct, member `%s` is not accessible", at, pathString(seq[:len(seq)-len(tail)-len(names)]), path, names[0]),
+					FixIt: "How To Fix:\n- check the path, members are accessible only in structs or pointers to structs.\n\n",
+				})
+			}
+
+			f := hseq.ForPath(hseq.Unfold[any](at), names...)
+			lens = Join[T, any, any](lens, &lensB{newField(at, f.Type, f.Indirect, f.Offset+f.RootOffs, nils), at})
+			at = f.Type
+		}
+	}
+
+	fv := reflect.TypeOf(new(A)).Elem()
+	if at.String() != fv.String() || !at.AssignableTo(fv) {
+		cat := reflect.TypeOf(new(T)).Elem()
+		panic(hseq.Error{
+			Issue: fmt.Sprintf("type `%s` of path `%s` in struct `%s` is not compatible with `%s`", at, path, cat, fv),
+			FixIt: fmt.Sprintf("How To Fix:\n- use the type of field `optics.ForPath[%s, %s](%q)`.\n\n", cat, at, path),
+		})
+	}
+
+	return Join[T, any, A](lens, lensA[A]{})
+}
+
+func compileKey(t reflect.Type, lit, path string, at []pathElem, nils Nil) (Lens[any, any], reflect.Type) {
+	if t.Kind() != reflect.Map {
+		panic(hseq.Error{
+			Issue: fmt.Sprintf("type `%s` at `%s` of path `%s` is not a map", t, pathString(at), path),
+			FixIt: "How To Fix:\n- check the path, brackets are allowed only for maps.\n\n",
+		})
+	}
+
+	key, ok := mapKey(t.Key(), lit)
+	if !ok {
+		panic(hseq.Error{
+			Issue: fmt.Sprintf("key %s of path `%s` is not compatible with `%s`", lit, path, t.Key()),
+			FixIt: "How To Fix:\n- quote string keys `A[\"key\"]`.\n- use decimal literals for integer keys `A[10]`.\n\n",
+		})
+	}
+
+	return &lensK{key: key, typeOf: t, nils: nils}, t.Elem()
+}
+
+// parse literal of map key into value of type t
+func mapKey(t reflect.Type, lit string) (reflect.Value, bool) {
+	key := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.String:
+		s, err := strconv.Unquote(lit)
+		if err != nil {
+			return key, false
+		}
+		key.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := strconv.ParseInt(lit, 10, t.Bits())
+		if err != nil {
+			return key, false
+		}
+		key.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := strconv.ParseUint(lit, 10, t.Bits())
+		if err != nil {
+			return key, false
+		}
+		key.SetUint(x)
+	default:
+		return key, false
+	}
+
+	return key, true
+}
+
+//------------------------------------------------------------------------------
+
+// element of path, the field name or literal of map key
+type pathElem struct {
+	name string
+	key  bool
+}
+
+func parsePath(path string) ([]pathElem, bool) {
+	seq := make([]pathElem, 0)
+
+	for s := path; len(s) > 0; {
+		if s[0] == '[' {
+			if len(seq) == 0 {
+				return nil, false
+			}
+
+			lit, err := strconv.QuotedPrefix(s[1:])
+			if err != nil {
+				lit = s[1:]
+				if n := strings.IndexByte(lit, ']'); n != -1 {
+					lit = lit[:n]
+				}
+			}
+
+			s = s[1+len(lit):]
+			if len(lit) == 0 || len(s) == 0 || s[0] != ']' {
+				return nil, false
+			}
+
+			seq = append(seq, pathElem{name: lit, key: true})
+			s = s[1:]
+			continue
+		}
+
+		if len(seq) > 0 {
+			if s[0] != '.' {
+				return nil, false
+			}
+			s = s[1:]
+		}
+
+		n := strings.IndexAny(s, ".[")
+		if n == -1 {
+			n = len(s)
+		}
+		if n == 0 {
+			return nil, false
+		}
+
+		seq = append(seq, pathElem{name: s[:n]})
+		s = s[n:]
+	}
+
+	return seq, len(seq) > 0
+}
+
+// leading field names of path and the rest of it
+func fieldsOf(seq []pathElem) ([]string, []pathElem) {
+	names := make([]string, 0, len(seq))
+	for len(seq) > 0 && !seq[0].key {
+		names = append(names, seq[0].name)
+		seq = seq[1:]
+	}
+	return names, seq
+}
+
+func pathString(seq []pathElem) string {
+	sb := strings.Builder{}
+	for i, x := range seq {
+		switch {
+		case x.key:
+			sb.WriteString("[" + x.name + "]")
+		case i > 0:
+			sb.WriteString("." + x.name)
+		default:
+			sb.WriteString(x.name)
+		}
+	}
+	return sb.String()
+}
+
+//------------------------------------------------------------------------------
+
+// Lenses of path with map keys, the type of focus is known at runtime only,
+// it is boxed into any. They are composed by Join.
+
+// field of runtime type at offset from the struct, pointers on the path
+// to the field are handled as lensP does.
+type field struct {
+	typeOf   reflect.Type
+	root     reflect.Type
+	indirect []hseq.Indirect
+	offset   uintptr
+	nils     Nil
+}
+
+// field of struct (or pointer to struct) of type s
+func newField(s, t reflect.Type, indirect []hseq.Indirect, offset uintptr, nils Nil) field {
+	f := field{typeOf: t, indirect: indirect, offset: offset, nils: nils}
+	if s.Kind() == reflect.Pointer {
+		f.root = s.Elem()
+	}
+	return f
+}
+
+func (f field) lookup(p unsafe.Pointer) (any, bool) {
+	if p = addrOf(p, f.root, f.indirect, f.offset, false); p != nil {
+		return reflect.NewAt(f.typeOf, p).Elem().Interface(), true
+	}
+	return reflect.Zero(f.typeOf).Interface(), false
+}
+
+func (f field) put(p unsafe.Pointer, a any) {
+	if p = addrOf(p, f.root, f.indirect, f.offset, f.nils == NilAlloc); p != nil {
+		reflect.NewAt(f.typeOf, p).Elem().Set(valueOf(a, f.typeOf))
+	}
+}
+
+// lensF is lens over the field of struct S
+type lensF[S any] struct{ field }
+
+func (lens *lensF[S]) Get(s *S) any            { a, _ := lens.lookup(s); return a }
+func (lens *lensF[S]) Put(s *S, a any) *S      { lens.put(unsafe.Pointer(s), a); return s }
+func (lens *lensF[S]) lookup(s *S) (any, bool) { return lens.field.lookup(unsafe.Pointer(s)) }
+
+// lensB is lens over the field of boxed struct (or pointer to struct),
+// the struct is copied, the copy is put back into the box.
+type lensB struct {
+	field
+	box reflect.Type
+}
+
+func (lens *lensB) Get(s *any) any { a, _ := lens.lookup(s); return a }
+
+func (lens *lensB) Put(s *any, a any) *any {
+	v := reflect.New(lens.box)
+	v.Elem().Set(valueOf(*s, lens.box))
+	lens.put(v.UnsafePointer(), a)
+	*s = v.Elem().Interface()
+	return s
+}
+
+func (lens *lensB) lookup(s *any) (any, bool) {
+	v := reflect.New(lens.box)
+	v.Elem().Set(valueOf(*s, lens.box))
+	return lens.field.lookup(v.UnsafePointer())
+}
+
+// lensK is lens over the key of boxed map, nil map is allocated by Put
+// if the policy is NilAlloc.
+type lensK struct {
+	key    reflect.Value
+	typeOf reflect.Type
+	nils   Nil
+}
+
+func (lens *lensK) Get(s *any) any { a, _ := lens.lookup(s); return a }
+
+func (lens *lensK) Put(s *any, a any) *any {
+	m := valueOf(*s, lens.typeOf)
+	if m.IsNil() {
+		if lens.nils != NilAlloc {
+			return s
+		}
+		m = reflect.MakeMap(lens.typeOf)
+	}
+
+	m.SetMapIndex(lens.key, valueOf(a, lens.typeOf.Elem()))
+	*s = m.Interface()
+	return s
+}
+
+func (lens *lensK) lookup(s *any) (any, bool) {
+	if v := valueOf(*s, lens.typeOf).MapIndex(lens.key); v.IsValid() {
+		return v.Interface(), true
+	}
+	return reflect.Zero(lens.typeOf.Elem()).Interface(), false
+}
+
+// lensA unboxes the focus of path
+type lensA[A any] struct{}
+
+func (lensA[A]) Get(s *any) A {
+	a, _ := (*s).(A)
+	return a
+}
+
+func (lensA[A]) Put(s *any, a A) *any {
+	*s = a
+	return s
+}
+
+// reflect value of type t, nil is casted to zero value
+func valueOf(x any, t reflect.Type) reflect.Value {
+	if x == nil {
+		return reflect.Zero(t)
+	}
+	return reflect.ValueOf(x)
+}
@@ -0,0 +1,203 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package optics_test
+
+import (
+	"testing"
+
+	"github.com/fogfish/golem/optics"
+	"github.com/fogfish/it/v2"
+)
+
+type Geo struct{ Lat, Lng float64 }
+
+type Address struct {
+	City string
+	Geo  *Geo
+}
+
+type Region struct{ Name string }
+
+type Config struct {
+	Name    string
+	Address Address
+	Tags    map[string]string
+	Regions map[string]Region
+	Ports   map[int]*Address
+	Meta    `hseq:"meta"`
+}
+
+func TestForPath(t *testing.T) {
+	t.Run("Field", func(t *testing.T) {
+		name := optics.ForPath[Config, string]("Name")
+		city := optics.ForPath[Config, string]("Address.City")
+		tag := optics.ForPath[Config, string]("Tag")
+
+		c := Config{Name: "name", Address: Address{City: "city"}, Meta: Meta{Tag: "tag"}}
+		it.Then(t).Should(
+			it.Equal(name.Get(&c), "name"),
+			it.Equal(city.Get(&c), "city"),
+			it.Equal(tag.Get(&c), "tag"),
+		)
+
+		city.Put(&c, "town")
+		it.Then(t).Should(
+			it.Equal(c.Address.City, "town"),
+		)
+	})
+
+	t.Run("Pointer", func(t *testing.T) {
+		lat := optics.ForPath[Config, float64]("Address.Geo.Lat")
+
+		c := Config{}
+		it.Then(t).Should(
+			it.Equal(lat.Get(&c), 0.0),
+		)
+
+		lat.Put(&c, 1.0)
+		it.Then(t).Should(
+			it.Equal(lat.Get(&c), 1.0),
+			it.Equiv(c.Address.Geo, &Geo{Lat: 1.0}),
+		)
+
+		pc := &c
+		plat := optics.ForPath[*Config, float64]("Address.Geo.Lat")
+		it.Then(t).Should(
+			it.Equal(plat.Get(&pc), 1.0),
+		)
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		env := optics.ForPath[Config, string](`Tags["env"]`)
+		region := optics.ForPath[Config, string](`Regions["eu"].Name`)
+		city := optics.ForPath[Config, string](`Ports[80].City`)
+
+		c := Config{}
+		it.Then(t).Should(
+			it.Equal(env.Get(&c), ""),
+			it.Equal(region.Get(&c), ""),
+			it.Equal(city.Get(&c), ""),
+		)
+
+		env.Put(&c, "prod")
+		region.Put(&c, "Europe")
+		city.Put(&c, "city")
+		it.Then(t).Should(
+			it.Equal(c.Tags["env"], "prod"),
+			it.Equal(c.Regions["eu"].Name, "Europe"),
+			it.Equal(c.Ports[80].City, "city"),
+			it.Equal(env.Get(&c), "prod"),
+			it.Equal(region.Get(&c), "Europe"),
+			it.Equal(city.Get(&c), "city"),
+		)
+	})
+
+	t.Run("NilSkip", func(t *testing.T) {
+		lat := optics.ForPathNil[Config, float64]("Address.Geo.Lat", optics.NilSkip)
+		env := optics.ForPathNil[Config, string](`Tags["env"]`, optics.NilSkip)
+
+		c := Config{}
+		lat.Put(&c, 1.0)
+		env.Put(&c, "prod")
+		it.Then(t).Should(
+			it.True(c.Address.Geo == nil),
+			it.True(c.Tags == nil),
+		)
+	})
+
+	t.Run("Maybe", func(t *testing.T) {
+		lat := optics.Maybe(optics.ForPath[Config, float64]("Address.Geo.Lat"))
+		env := optics.Maybe(optics.ForPath[Config, string](`Tags["env"]`))
+
+		c := Config{}
+		_, hasLat := lat.Preview(&c)
+		_, hasEnv := env.Preview(&c)
+		it.Then(t).ShouldNot(
+			it.True(hasLat),
+			it.True(hasEnv),
+		)
+
+		c = Config{Address: Address{Geo: &Geo{Lat: 1.0}}, Tags: map[string]string{"env": "prod"}}
+		vLat, hasLat := lat.Preview(&c)
+		vEnv, hasEnv := env.Preview(&c)
+		it.Then(t).Should(
+			it.True(hasLat),
+			it.Equal(vLat, 1.0),
+			it.True(hasEnv),
+			it.Equal(vEnv, "prod"),
+		)
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		addr := optics.ForPath[Config, Address]("Address")
+		lat := optics.Join(addr, optics.ForPath[Address, float64]("Geo.Lat"))
+
+		c := Config{}
+		lat.Put(&c, 1.0)
+		it.Then(t).Should(
+			it.Equal(c.Address.Geo.Lat, 1.0),
+		)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		a := optics.ForPath[Config, string]("Address.City")
+		b := optics.ForPath[Config, string]("Address.City")
+
+		c := Config{}
+		a.Put(&c, "city")
+		it.Then(t).Should(
+			it.Equal(b.Get(&c), "city"),
+		)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, path := range []string{
+			"",
+			".Name",
+			"Name.",
+			"Address..City",
+			`["env"]`,
+			`Tags["env"`,
+			`Tags[]`,
+			`Tags["env"]City`,
+		} {
+			it.Then(t).Should(
+				it.Fail(
+					func() { optics.ForPath[Config, string](path) },
+				).Contain("Critical Error"),
+			)
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Fail(
+				func() { optics.ForPath[Config, string]("Address.Town") },
+			).Contain("is not member of a struct"),
+			it.Fail(
+				func() { optics.ForPath[Config, string]("Name.City") },
+			).Contain("is not a struct"),
+			it.Fail(
+				func() { optics.ForPath[Config, string](`Name["env"]`) },
+			).Contain("is not a map"),
+			it.Fail(
+				func() { optics.ForPath[Config, string](`Ports["80"].City`) },
+			).Contain("is not compatible"),
+			it.Fail(
+				func() { optics.ForPath[Config, int]("Address.City") },
+			).Contain("not compatible"),
+			it.Fail(
+				func() { optics.ForPath[Config, int](`Tags["env"]`) },
+			).Contain("is not compatible"),
+			it.Fail(
+				func() { optics.ForPath[Config, string](`Tags["env"].City`) },
+			).Contain("is not a struct"),
+		)
+	})
+}
@@ -0,0 +1,125 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package optics
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Prism is Ω-lens, it focuses on the part of structure, which might not exist
+// (e.g. variant of sum type, nil pointer). Preview returns the focus if it
+// exists, Review puts the focus into the structure creating it if needed.
+type Prism[S, A any] interface {
+	Preview(*S) (A, bool)
+	Review(*S, A) *S
+}
+
+// NewPrism instantiates a Prism[S, A] from functions
+func NewPrism[S, A any](
+	preview func(*S) (A, bool),
+	review func(*S, A) *S,
+) Prism[S, A] {
+	return prism[S, A]{preview: preview, review: review}
+}
+
+type prism[S, A any] struct {
+	preview func(*S) (A, bool)
+	review  func(*S, A) *S
+}
+
+func (p prism[S, A]) Preview(s *S) (A, bool) { return p.preview(s) }
+func (p prism[S, A]) Review(s *S, a A) *S    { return p.review(s, a) }
+
+// Variant builds Prism[S, A] over the field of interface type I, the focus
+// exists if the field holds the value of type A. The type A must implement I,
+// it panics otherwise.
+//
+//	type Envelope struct { Payload any }
+//	circle := optics.Variant[Envelope, any, Circle](optics.ForProduct1[Envelope, any]())
+func Variant[S, I, A any](lens Lens[S, I]) Prism[S, A] {
+	ti := reflect.TypeOf(new(I)).Elem()
+	ta := reflect.TypeOf(new(A)).Elem()
+	if ti.Kind() != reflect.Interface || !ta.Implements(ti) {
+		panic(fmt.Errorf("invalid type: Variant[%s, %s, %s] %s does not implement %s",
+			reflect.TypeOf(new(S)).Elem(), ti, ta, ta, ti))
+	}
+
+	return variant[S, I, A]{lens}
+}
+
+type variant[S, I, A any] struct{ lens Lens[S, I] }
+
+func (p variant[S, I, A]) Preview(s *S) (A, bool) {
+	a, ok := any(p.lens.Get(s)).(A)
+	return a, ok
+}
+
+func (p variant[S, I, A]) Review(s *S, a A) *S {
+	return p.lens.Put(s, any(a).(I))
+}
+
+// Some builds Prism[S, A] over the pointer field, the focus exists if
+// the pointer is not nil. Review puts the pointer to copy of A.
+func Some[S, A any](lens Lens[S, *A]) Prism[S, A] {
+	return some[S, A]{lens}
+}
+
+type some[S, A any] struct{ lens Lens[S, *A] }
+
+func (p some[S, A]) Preview(s *S) (A, bool) {
+	if a := p.lens.Get(s); a != nil {
+		return *a, true
+	}
+	return *new(A), false
+}
+
+func (p some[S, A]) Review(s *S, a A) *S {
+	return p.lens.Put(s, &a)
+}
+
+// Maybe builds Prism[S, A] over the lens, the focus exists if it is reachable:
+// pointers on the path to the field are not nil, the key exists in the map.
+// Review puts the focus using the lens, see Nil policy.
+func Maybe[S, A any](lens Lens[S, A]) Prism[S, A] {
+	return maybe[S, A]{lens}
+}
+
+type maybe[S, A any] struct{ lens Lens[S, A] }
+
+func (p maybe[S, A]) Preview(s *S) (A, bool) { return lookup(p.lens, s) }
+func (p maybe[S, A]) Review(s *S, a A) *S    { return p.lens.Put(s, a) }
+
+// Optional adapts Prism[S, A] to Lens[S, A], which focus might not exist.
+// Get returns zero value of A if the focus does not exist, Put reviews
+// the focus only if it exists, the structure is unchanged otherwise. Prisms
+// are composed with lenses and other prisms by Join, the existence of focus
+// is preserved, use Maybe to preview it. Use Review of prism to build
+// the focus from nothing.
+//
+//	optics.Maybe(optics.Join(optics.Optional(circle), radius))
+func Optional[S, A any](prism Prism[S, A]) Lens[S, A] {
+	return optional[S, A]{prism}
+}
+
+type optional[S, A any] struct{ prism Prism[S, A] }
+
+func (lens optional[S, A]) Get(s *S) A {
+	a, _ := lens.prism.Preview(s)
+	return a
+}
+
+func (lens optional[S, A]) Put(s *S, a A) *S {
+	if _, has := lens.prism.Preview(s); !has {
+		return s
+	}
+	return lens.prism.Review(s, a)
+}
+
+func (lens optional[S, A]) lookup(s *S) (A, bool) { return lens.prism.Preview(s) }
@@ -0,0 +1,210 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package optics_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fogfish/golem/optics"
+	"github.com/fogfish/it/v2"
+)
+
+type Circle struct{ Radius float64 }
+type Square struct{ Side float64 }
+type Meta struct{ Tag string }
+
+type Envelope struct {
+	ID    string
+	Shape any
+	Meta  *Meta
+}
+
+var (
+	shape, meta = optics.ForProduct2[Envelope, any, *Meta]()
+	radius      = optics.ForProduct1[Circle, float64]()
+	tag         = optics.ForProduct1[Meta, string]()
+	circle      = optics.Variant[Envelope, any, Circle](shape)
+	square      = optics.Variant[Envelope, any, Square](shape)
+)
+
+func TestPrism(t *testing.T) {
+	t.Run("Variant", func(t *testing.T) {
+		e := Envelope{Shape: Circle{Radius: 1.0}}
+
+		c, okc := circle.Preview(&e)
+		_, oks := square.Preview(&e)
+		it.Then(t).Should(
+			it.True(okc),
+			it.Equal(c.Radius, 1.0),
+		).ShouldNot(
+			it.True(oks),
+		)
+
+		square.Review(&e, Square{Side: 2.0})
+		s, oks := square.Preview(&e)
+		_, okc = circle.Preview(&e)
+		it.Then(t).Should(
+			it.True(oks),
+			it.Equal(s.Side, 2.0),
+		).ShouldNot(
+			it.True(okc),
+		)
+	})
+
+	t.Run("VariantInvalid", func(t *testing.T) {
+		type Box struct {
+			Label fmt.Stringer
+			Shape Square
+		}
+		label, shape := optics.ForProduct2[Box, fmt.Stringer, Square]()
+
+		it.Then(t).Should(
+			it.Fail(func() { optics.Variant[Box, fmt.Stringer, Circle](label) }).Contain("does not implement"),
+			it.Fail(func() { optics.Variant[Box, Square, Circle](shape) }).Contain("does not implement"),
+		)
+	})
+
+	t.Run("Some", func(t *testing.T) {
+		m := optics.Some(meta)
+
+		e := Envelope{}
+		_, ok := m.Preview(&e)
+		it.Then(t).ShouldNot(it.True(ok))
+
+		m.Review(&e, Meta{Tag: "a"})
+		v, ok := m.Preview(&e)
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(v.Tag, "a"),
+			it.Equal(e.Meta.Tag, "a"),
+		)
+	})
+
+	t.Run("Maybe", func(t *testing.T) {
+		m := optics.Maybe(optics.Join(meta, optics.ForProduct1[*Meta, string]()))
+
+		e := Envelope{}
+		_, ok := m.Preview(&e)
+		it.Then(t).ShouldNot(it.True(ok))
+
+		m.Review(&e, "a")
+		v, ok := m.Preview(&e)
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(v, "a"),
+			it.Equal(e.Meta.Tag, "a"),
+		)
+	})
+
+	t.Run("MaybeMap", func(t *testing.T) {
+		m := optics.Maybe(optics.NewLensM[map[string]int]("a"))
+
+		s := map[string]int{}
+		_, ok := m.Preview(&s)
+		it.Then(t).ShouldNot(it.True(ok))
+
+		m.Review(&s, 0)
+		v, ok := m.Preview(&s)
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(v, 0),
+		)
+	})
+
+	t.Run("NewPrism", func(t *testing.T) {
+		id := optics.NewPrism(
+			func(e *Envelope) (string, bool) { return e.ID, e.ID != "" },
+			func(e *Envelope, id string) *Envelope { e.ID = id; return e },
+		)
+
+		e := Envelope{}
+		_, ok := id.Preview(&e)
+		it.Then(t).ShouldNot(it.True(ok))
+
+		id.Review(&e, "id")
+		v, ok := id.Preview(&e)
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(v, "id"),
+		)
+	})
+
+	t.Run("JoinPrismLens", func(t *testing.T) {
+		r := optics.Maybe(optics.Join(optics.Optional(circle), radius))
+
+		e := Envelope{Shape: Square{Side: 1.0}}
+		_, ok := r.Preview(&e)
+		it.Then(t).ShouldNot(it.True(ok))
+
+		r.Review(&e, 2.0)
+		it.Then(t).Should(
+			it.Equiv(e.Shape, any(Square{Side: 1.0})),
+		)
+
+		e = Envelope{Shape: Circle{Radius: 1.0}}
+		r.Review(&e, 2.0)
+		v, ok := r.Preview(&e)
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(v, 2.0),
+			it.Equiv(e.Shape, any(Circle{Radius: 2.0})),
+		)
+	})
+
+	t.Run("JoinLensPrism", func(t *testing.T) {
+		type Box struct{ Envelope Envelope }
+		r := optics.Maybe(optics.Join(optics.ForProduct1[Box, Envelope](), optics.Optional(circle)))
+
+		b := Box{}
+		_, ok := r.Preview(&b)
+		it.Then(t).ShouldNot(it.True(ok))
+
+		r.Review(&b, Circle{Radius: 3.0})
+		it.Then(t).Should(
+			it.Nil(b.Envelope.Shape),
+		)
+
+		b = Box{Envelope: Envelope{Shape: Circle{Radius: 1.0}}}
+		r.Review(&b, Circle{Radius: 3.0})
+		v, ok := r.Preview(&b)
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(v.Radius, 3.0),
+		)
+	})
+
+	t.Run("JoinPrismPrism", func(t *testing.T) {
+		type Box struct{ Envelope *Envelope }
+		r := optics.Maybe(
+			optics.Join(
+				optics.Optional(optics.Some(optics.ForProduct1[Box, *Envelope]())),
+				optics.Join(optics.Optional(optics.Some(meta)), tag),
+			),
+		)
+
+		b := Box{}
+		_, ok := r.Preview(&b)
+		it.Then(t).ShouldNot(it.True(ok))
+
+		r.Review(&b, "tag")
+		it.Then(t).Should(
+			it.True(b.Envelope == nil),
+		)
+
+		b = Box{Envelope: &Envelope{Meta: &Meta{}}}
+		r.Review(&b, "tag")
+		v, ok := r.Preview(&b)
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(v, "tag"),
+			it.Equal(b.Envelope.Meta.Tag, "tag"),
+		)
+	})
+}
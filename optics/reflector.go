@@ -42,12 +42,34 @@ func (lens *lens[S, A]) Gett(s any) A {
 	}
 }
 
+func (lens *lensP[S, A]) Putt(s any, a A) any {
+	switch v := s.(type) {
+	case *S:
+		lens.Put(v, a)
+		return s
+	default:
+		panic(fmt.Errorf("invalid type %T passed to Reflector[%T, %T]", s, *new(S), *new(A)))
+	}
+}
+
+func (lens *lensP[S, A]) Gett(s any) A {
+	switch v := s.(type) {
+	case *S:
+		return lens.Get(v)
+	default:
+		panic(fmt.Errorf("invalid type %T passed to Reflector[%T, %T]", s, *new(S), *new(A)))
+	}
+}
+
 // NewReflector instantiates a typed Reflector[S, A] for hseq.Type[S]
 func NewReflector[S, A any](t hseq.Type[S]) Reflector[A] {
 	ft := t.Type
 	fv := reflect.TypeOf(new(A)).Elem()
 
 	if ft.String() == fv.String() && ft.AssignableTo(fv) {
+		if len(t.Indirect) != 0 {
+			return &lensP[S, A]{Type: t, nils: NilAlloc}
+		}
 		return &lens[S, A]{t}
 	}
 
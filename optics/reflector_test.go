@@ -112,7 +112,6 @@ func TestReflectorLaws(t *testing.T) {
 		type T struct {
 			A
 			B
-			// Note: lens package does not support embedded pointers
 		}
 
 		la := optics.ForSpectrum1[T, S]()
@@ -150,6 +149,23 @@ func TestReflectorLaws(t *testing.T) {
 			)
 		})
 	})
+
+	t.Run("EmbeddedPointer", func(t *testing.T) {
+		type B struct{ I int }
+		type T struct {
+			A string
+			*B
+		}
+
+		lb := optics.ForSpectrum1[T, int]("I")
+
+		tt := T{}
+		it.Then(t).Should(
+			it.Equal(lb.Gett(&tt), 0),
+			it.Equal(lb.Gett(lb.Putt(&tt, 10)), 10),
+			it.Equiv(tt, T{B: &B{10}}),
+		)
+	})
 }
 
 func TestReflectorIncompatibility(t *testing.T) {
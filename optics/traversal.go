@@ -0,0 +1,151 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package optics
+
+import "github.com/fogfish/golem/pure/monoid"
+
+// Traversal focuses on zero or more parts of structure (e.g. elements of
+// slice, values of map). GetAll returns all foci, Modify updates each focus
+// with the function, Fold combines foci with the monoid.
+type Traversal[S, A any] interface {
+	GetAll(*S) []A
+	Modify(*S, func(A) A) *S
+	Fold(*S, monoid.Monoid[A]) A
+}
+
+func fold[A any](seq []A, m monoid.Monoid[A]) A {
+	acc := m.Empty()
+	for _, x := range seq {
+		acc = m.Combine(acc, x)
+	}
+	return acc
+}
+
+// Each instantiates a Traversal[S, A] over elements of slice []A
+func Each[S interface{ ~[]A }, A any]() Traversal[S, A] {
+	return each[S, A]{}
+}
+
+type each[S interface{ ~[]A }, A any] struct{}
+
+func (each[S, A]) GetAll(s *S) []A {
+	return append([]A{}, (*s)...)
+}
+
+func (each[S, A]) Modify(s *S, f func(A) A) *S {
+	for i, x := range *s {
+		(*s)[i] = f(x)
+	}
+	return s
+}
+
+func (each[S, A]) Fold(s *S, m monoid.Monoid[A]) A { return fold(*s, m) }
+
+// EachValue instantiates a Traversal[S, A] over values of map[K]A. The order
+// of values is not defined.
+func EachValue[S interface{ ~map[K]A }, K comparable, A any]() Traversal[S, A] {
+	return eachValue[S, K, A]{}
+}
+
+type eachValue[S interface{ ~map[K]A }, K comparable, A any] struct{}
+
+func (eachValue[S, K, A]) GetAll(s *S) []A {
+	seq := make([]A, 0, len(*s))
+	for _, x := range *s {
+		seq = append(seq, x)
+	}
+	return seq
+}
+
+func (eachValue[S, K, A]) Modify(s *S, f func(A) A) *S {
+	for k, x := range *s {
+		(*s)[k] = f(x)
+	}
+	return s
+}
+
+func (t eachValue[S, K, A]) Fold(s *S, m monoid.Monoid[A]) A { return fold(t.GetAll(s), m) }
+
+// JoinLT composes Lens[S, A] with Traversal[A, B]
+func JoinLT[S, A, B any](a Lens[S, A], b Traversal[A, B]) Traversal[S, B] {
+	return joinLT[S, A, B]{a, b}
+}
+
+type joinLT[S, A, B any] struct {
+	a Lens[S, A]
+	b Traversal[A, B]
+}
+
+func (t joinLT[S, A, B]) GetAll(s *S) []B {
+	va := t.a.Get(s)
+	return t.b.GetAll(&va)
+}
+
+func (t joinLT[S, A, B]) Modify(s *S, f func(B) B) *S {
+	va := t.a.Get(s)
+	t.b.Modify(&va, f)
+	return t.a.Put(s, va)
+}
+
+func (t joinLT[S, A, B]) Fold(s *S, m monoid.Monoid[B]) B { return fold(t.GetAll(s), m) }
+
+// JoinTL composes Traversal[S, A] with Lens[A, B]
+func JoinTL[S, A, B any](a Traversal[S, A], b Lens[A, B]) Traversal[S, B] {
+	return joinTL[S, A, B]{a, b}
+}
+
+type joinTL[S, A, B any] struct {
+	a Traversal[S, A]
+	b Lens[A, B]
+}
+
+func (t joinTL[S, A, B]) GetAll(s *S) []B {
+	seq := t.a.GetAll(s)
+	out := make([]B, len(seq))
+	for i := range seq {
+		out[i] = t.b.Get(&seq[i])
+	}
+	return out
+}
+
+func (t joinTL[S, A, B]) Modify(s *S, f func(B) B) *S {
+	return t.a.Modify(s, func(x A) A {
+		t.b.Put(&x, f(t.b.Get(&x)))
+		return x
+	})
+}
+
+func (t joinTL[S, A, B]) Fold(s *S, m monoid.Monoid[B]) B { return fold(t.GetAll(s), m) }
+
+// JoinTT composes Traversal[S, A] with Traversal[A, B]
+func JoinTT[S, A, B any](a Traversal[S, A], b Traversal[A, B]) Traversal[S, B] {
+	return joinTT[S, A, B]{a, b}
+}
+
+type joinTT[S, A, B any] struct {
+	a Traversal[S, A]
+	b Traversal[A, B]
+}
+
+func (t joinTT[S, A, B]) GetAll(s *S) []B {
+	out := make([]B, 0)
+	for _, x := range t.a.GetAll(s) {
+		out = append(out, t.b.GetAll(&x)...)
+	}
+	return out
+}
+
+func (t joinTT[S, A, B]) Modify(s *S, f func(B) B) *S {
+	return t.a.Modify(s, func(x A) A {
+		t.b.Modify(&x, f)
+		return x
+	})
+}
+
+func (t joinTT[S, A, B]) Fold(s *S, m monoid.Monoid[B]) B { return fold(t.GetAll(s), m) }
@@ -0,0 +1,104 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package optics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/golem/optics"
+	"github.com/fogfish/golem/pure/monoid"
+	"github.com/fogfish/it/v2"
+)
+
+type Item struct {
+	Name  string
+	Price int
+}
+
+type Order struct {
+	ID    string
+	Items []Item
+	Tags  map[string]string
+}
+
+type Orders []Order
+
+var sum = monoid.FromOp(0, func(a, b int) int { return a + b })
+
+func TestTraversal(t *testing.T) {
+	items, tags := optics.ForProduct2[Order, []Item, map[string]string]()
+	price := optics.ForProduct1[Item, int]()
+
+	t.Run("Each", func(t *testing.T) {
+		seq := []int{1, 2, 3}
+		each := optics.Each[[]int]()
+
+		each.Modify(&seq, func(x int) int { return x * 10 })
+		it.Then(t).Should(
+			it.Seq(each.GetAll(&seq)).Equal(10, 20, 30),
+			it.Equal(each.Fold(&seq, sum), 60),
+		)
+	})
+
+	t.Run("EachValue", func(t *testing.T) {
+		kv := map[string]int{"a": 1, "b": 2}
+		each := optics.EachValue[map[string]int]()
+
+		each.Modify(&kv, func(x int) int { return x + 1 })
+		it.Then(t).Should(
+			it.Seq(each.GetAll(&kv)).Contain().AllOf(2, 3),
+			it.Equal(each.Fold(&kv, sum), 5),
+		)
+	})
+
+	t.Run("JoinLT", func(t *testing.T) {
+		order := Order{Tags: map[string]string{"a": "x", "b": "y"}}
+		values := optics.JoinLT(tags, optics.EachValue[map[string]string]())
+
+		values.Modify(&order, strings.ToUpper)
+		it.Then(t).Should(
+			it.Seq(values.GetAll(&order)).Contain().AllOf("X", "Y"),
+			it.Equal(order.Tags["a"], "X"),
+		)
+	})
+
+	t.Run("JoinTL", func(t *testing.T) {
+		order := Order{Items: []Item{{"a", 1}, {"b", 2}}}
+		prices := optics.JoinTL(optics.JoinLT(items, optics.Each[[]Item]()), price)
+
+		prices.Modify(&order, func(x int) int { return x * 2 })
+		it.Then(t).Should(
+			it.Seq(prices.GetAll(&order)).Equal(2, 4),
+			it.Equal(prices.Fold(&order, sum), 6),
+			it.Equal(order.Items[1].Price, 4),
+		)
+	})
+
+	t.Run("JoinTT", func(t *testing.T) {
+		orders := Orders{
+			{Items: []Item{{"a", 1}, {"b", 2}}},
+			{Items: []Item{{"c", 3}}},
+		}
+		prices := optics.JoinTL(
+			optics.JoinTT(
+				optics.JoinTL(optics.Each[Orders](), items),
+				optics.Each[[]Item](),
+			),
+			price,
+		)
+
+		prices.Modify(&orders, func(x int) int { return x + 1 })
+		it.Then(t).Should(
+			it.Seq(prices.GetAll(&orders)).Equal(2, 3, 4),
+			it.Equal(prices.Fold(&orders, sum), 9),
+			it.Equal(orders[1].Items[0].Price, 4),
+		)
+	})
+}
@@ -0,0 +1,321 @@
+//
+// Copyright (C) 2022 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package pipe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Ack is an acknowledgement tracking envelope of value T. The envelope keeps
+// the link to the source message, which is committed only after all derived
+// envelopes are acknowledged (done or dropped).
+type Ack[T any] struct {
+	Value T
+	ref   *ackref
+}
+
+// Done acknowledges the envelope. Each envelope must be acknowledged once.
+func (a Ack[T]) Done() {
+	if a.ref != nil {
+		a.ref.release()
+	}
+}
+
+// AckWith derives a new envelope of value b linked to the same source message
+// as the envelope a. The derived envelope has to be acknowledged independently.
+func AckWith[A, B any](a Ack[A], b B) Ack[B] {
+	if a.ref != nil {
+		a.ref.retain()
+	}
+	return Ack[B]{Value: b, ref: a.ref}
+}
+
+// reference counter of envelopes derived from the source message
+type ackref struct {
+	refs atomic.Int64
+	done func()
+}
+
+func (ref *ackref) retain() { ref.refs.Add(1) }
+
+func (ref *ackref) release() {
+	if ref.refs.Add(-1) == 0 {
+		ref.done()
+	}
+}
+
+// Track wraps messages of the input channel into acknowledgement tracking
+// envelopes. It returns channel of envelopes and channel of commits. The commit
+// channel emits source messages in the order of input channel as soon as
+// the message and all its predecessors are acknowledged, so that the latest
+// emitted message is the commit watermark of the source.
+//
+// The commit channel must be consumed. Messages of the input channel are not
+// admitted while cap(in) envelopes are in-flight or pending commit. Stages
+// must not withhold acknowledgement of more than cap(in) envelopes (e.g.
+// batching), otherwise the pipeline stalls.
+//
+// Each envelope must be acknowledged, including envelopes dropped by stages
+// (e.g. Filter, TakeWhile). Otherwise, the watermark stalls forever. Use
+// AckFilter to acknowledge envelopes rejected by Filter.
+//
+// The commit channel is closed when input channel is closed and all envelopes
+// are acknowledged, or when the context is cancelled.
+func Track[T any](ctx context.Context, in <-chan T) (<-chan Ack[T], <-chan T) {
+	out := make(chan Ack[T], cap(in))
+	eg := make(chan T, cap(in))
+
+	t := &tracker[T]{
+		limit:    max(cap(in), 1),
+		inflight: make(map[uint64]T),
+		acked:    make(map[uint64]struct{}),
+		kick:     make(chan struct{}, 1),
+		room:     make(chan struct{}, 1),
+	}
+
+	go func() {
+		defer close(out)
+
+		var x T
+		for x = range in {
+			if !t.admit(ctx) {
+				return
+			}
+
+			ack := Ack[T]{Value: x, ref: t.track(x)}
+
+			select {
+			case out <- ack:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		t.mu.Lock()
+		t.eof = true
+		t.mu.Unlock()
+		t.notify()
+	}()
+
+	go func() {
+		defer close(eg)
+
+		for {
+			select {
+			case <-t.kick:
+			case <-ctx.Done():
+				return
+			}
+
+			t.mu.Lock()
+			seq := t.commits
+			t.commits = nil
+			eof := t.eof && t.low == t.seq
+			t.mu.Unlock()
+
+			select {
+			case t.room <- struct{}{}:
+			default:
+			}
+
+			for _, x := range seq {
+				select {
+				case eg <- x:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if eof {
+				return
+			}
+		}
+	}()
+
+	return out, eg
+}
+
+type tracker[T any] struct {
+	mu       sync.Mutex
+	limit    int    // number of in-flight envelopes and pending commits
+	seq      uint64 // sequence number of next message
+	low      uint64 // sequence number of next message to commit
+	eof      bool
+	inflight map[uint64]T
+	acked    map[uint64]struct{}
+	commits  []T
+	kick     chan struct{}
+	room     chan struct{}
+}
+
+// admit blocks while the number of in-flight envelopes and pending commits
+// reaches the limit. Messages in-flight are [low, seq), it bounds both
+// inflight and acked.
+func (t *tracker[T]) admit(ctx context.Context) bool {
+	for {
+		t.mu.Lock()
+		n := int(t.seq-t.low) + len(t.commits)
+		t.mu.Unlock()
+
+		if n < t.limit {
+			return true
+		}
+
+		select {
+		case <-t.room:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (t *tracker[T]) track(x T) *ackref {
+	t.mu.Lock()
+	id := t.seq
+	t.seq++
+	t.inflight[id] = x
+	t.mu.Unlock()
+
+	ref := &ackref{done: func() { t.ack(id) }}
+	ref.refs.Store(1)
+	return ref
+}
+
+func (t *tracker[T]) ack(id uint64) {
+	t.mu.Lock()
+	t.acked[id] = struct{}{}
+	for {
+		if _, has := t.acked[t.low]; !has {
+			break
+		}
+		t.commits = append(t.commits, t.inflight[t.low])
+		delete(t.acked, t.low)
+		delete(t.inflight, t.low)
+		t.low++
+	}
+	t.mu.Unlock()
+	t.notify()
+}
+
+func (t *tracker[T]) notify() {
+	select {
+	case t.kick <- struct{}{}:
+	default:
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// AckF lifts morphism 𝑓: A ⟼ B over acknowledgement tracking envelopes.
+// The output envelope inherits the link to the source message of input one.
+// The envelope is acknowledged (dropped) if morphism fails.
+func AckF[A, B any](f F[A, B]) F[Ack[A], Ack[B]] {
+	return ackf[A, B]{f}
+}
+
+type ackf[A, B any] struct{ f F[A, B] }
+
+func (f ackf[A, B]) Apply(a Ack[A]) (Ack[B], error) {
+	b, err := f.f.Apply(a.Value)
+	if err != nil {
+		a.Done()
+		return Ack[B]{}, err
+	}
+
+	return Ack[B]{Value: b, ref: a.ref}, nil
+}
+
+//lint:ignore U1000 false positive
+func (f ackf[A, B]) errch(cap int) chan error {
+	return f.f.errch(cap)
+}
+
+//lint:ignore U1000 false positive
+func (f ackf[A, B]) catch(ctx context.Context, err error, exx chan<- error) bool {
+	return f.f.catch(ctx, err, exx)
+}
+
+// AckFilter lifts predicate over acknowledgement tracking envelopes for Filter
+// and FilterE. The envelope is acknowledged (dropped) if predicate rejects it
+// or fails. It is not applicable to Partition and TakeWhile, these stages
+// do not drop elements rejected by predicate or drop elements without
+// applying predicate.
+func AckFilter[A any](f F[A, bool]) F[Ack[A], bool] {
+	return ackp[A]{f}
+}
+
+type ackp[A any] struct{ f F[A, bool] }
+
+func (f ackp[A]) Apply(a Ack[A]) (bool, error) {
+	take, err := f.f.Apply(a.Value)
+	if !take || err != nil {
+		a.Done()
+	}
+	return take, err
+}
+
+//lint:ignore U1000 false positive
+func (f ackp[A]) errch(cap int) chan error {
+	return f.f.errch(cap)
+}
+
+//lint:ignore U1000 false positive
+func (f ackp[A]) catch(ctx context.Context, err error, exx chan<- error) bool {
+	return f.f.catch(ctx, err, exx)
+}
+
+// AckFF lifts functor morphism 𝓕: A ⟼ B over acknowledgement tracking envelopes.
+// Each emitted element is the derived envelope linked to the source message of
+// input one. The input envelope is acknowledged when morphism completes, so that
+// the source message is committed when all derived envelopes are acknowledged.
+func AckFF[A, B any](f FF[A, B]) FF[Ack[A], Ack[B]] {
+	return ackff[A, B]{f}
+}
+
+type ackff[A, B any] struct{ f FF[A, B] }
+
+func (f ackff[A, B]) Apply(ctx context.Context, a Ack[A], out chan<- Ack[B]) error {
+	ch := make(chan B)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		var b B
+		for b = range ch {
+			ack := AckWith(a, b)
+
+			select {
+			case out <- ack:
+			case <-ctx.Done():
+				// Note: the envelope is not acknowledged, the source message is
+				// never committed. The channel is drained to unblock the morphism.
+			}
+		}
+	}()
+
+	err := f.f.Apply(ctx, a.Value, ch)
+	close(ch)
+	<-done
+
+	a.Done()
+	return err
+}
+
+//lint:ignore U1000 false positive
+func (f ackff[A, B]) errch(cap int) chan error {
+	return f.f.errch(cap)
+}
+
+//lint:ignore U1000 false positive
+func (f ackff[A, B]) catch(ctx context.Context, err error, exx chan<- error) bool {
+	return f.f.catch(ctx, err, exx)
+}
@@ -0,0 +1,227 @@
+//
+// Copyright (C) 2022 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package pipe_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fogfish/golem/pipe/v2"
+	"github.com/fogfish/it/v2"
+)
+
+func TestTrack(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := pipe.Track(ctx, pipe.Seq(1, 2, 3, 4, 5))
+		out := pipe.StdErr(pipe.Map(ctx, acks,
+			pipe.AckF(pipe.Pure(func(x int) string { return fmt.Sprintf("x%d", x) })),
+		))
+
+		seq := pipe.ToSeq(out)
+		vals := make([]string, 0)
+		for i := len(seq) - 1; i >= 0; i-- {
+			vals = append(vals, seq[i].Value)
+			seq[i].Done()
+		}
+
+		it.Then(t).Should(
+			it.Seq(vals).Equal("x5", "x4", "x3", "x2", "x1"),
+			it.Seq(pipe.ToSeq(commits)).Equal(1, 2, 3, 4, 5),
+		)
+
+		close()
+	})
+
+	t.Run("Watermark", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := pipe.Track(ctx, pipe.Seq(1, 2, 3))
+
+		a1, a2, a3 := <-acks, <-acks, <-acks
+
+		a3.Done()
+		a1.Done()
+		it.Then(t).Should(
+			it.Equal(<-commits, 1),
+		)
+
+		a2.Done()
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(commits)).Equal(2, 3),
+		)
+
+		close()
+	})
+
+	t.Run("Try", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := pipe.Track(ctx, pipe.Seq(1, 2, 3))
+		out, exx := pipe.Map(ctx, acks,
+			pipe.AckF(pipe.Try(func(x int) (int, error) {
+				if x == 2 {
+					return 0, fmt.Errorf("fail")
+				}
+				return x, nil
+			})),
+		)
+
+		seq := pipe.ToSeq(out)
+		for _, x := range seq {
+			x.Done()
+		}
+
+		it.Then(t).Should(
+			it.Equal(len(seq), 2),
+			it.Seq(pipe.ToSeq(exx)).Equal(fmt.Errorf("fail")),
+			it.Seq(pipe.ToSeq(commits)).Equal(1, 2, 3),
+		)
+
+		close()
+	})
+
+	t.Run("FMap", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := pipe.Track(ctx, pipe.Seq(1, 2, 3))
+		out := pipe.StdErr(pipe.FMap(ctx, acks,
+			pipe.AckFF(pipe.LiftF(func(ctx context.Context, x int, ch chan<- int) error {
+				for i := 1; i < x; i++ {
+					ch <- x*10 + i
+				}
+				return nil
+			})),
+		))
+
+		a1 := <-out
+		a2 := <-out
+		a3 := <-out
+
+		it.Then(t).Should(
+			it.Equal(a1.Value, 21),
+			it.Equal(a2.Value, 31),
+			it.Equal(a3.Value, 32),
+			// the first message is dropped, it has no derived envelopes
+			it.Equal(<-commits, 1),
+		)
+
+		a3.Done()
+		a1.Done()
+		it.Then(t).Should(
+			it.Equal(<-commits, 2),
+		)
+
+		a2.Done()
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(commits)).Equal(3),
+		)
+
+		close()
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := pipe.Track(ctx, pipe.Seq(1, 2, 3, 4))
+		out := pipe.Filter(ctx, acks,
+			pipe.AckFilter(pipe.Pure(func(x int) bool { return x%2 == 0 })),
+		)
+
+		seq := pipe.ToSeq(out)
+		for _, x := range seq {
+			x.Done()
+		}
+
+		it.Then(t).Should(
+			it.Equal(len(seq), 2),
+			it.Seq(pipe.ToSeq(commits)).Equal(1, 2, 3, 4),
+		)
+
+		close()
+	})
+
+	t.Run("Backpressure", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		in := make(chan int, 1)
+		acks, commits := pipe.Track(ctx, in)
+
+		// commit channel and pending commits are full, next message is not admitted
+		for i := 1; i <= 3; i++ {
+			in <- i
+			(<-acks).Done()
+		}
+		in <- 4
+
+		select {
+		case <-acks:
+			t.Error("message is admitted")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		it.Then(t).Should(
+			it.Equal(<-commits, 1),
+		)
+
+		(<-acks).Done()
+		it.Then(t).Should(
+			it.Equal(<-commits, 2),
+			it.Equal(<-commits, 3),
+			it.Equal(<-commits, 4),
+		)
+
+		close()
+	})
+
+	t.Run("Inflight", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		in := make(chan int, 2)
+		acks, commits := pipe.Track(ctx, in)
+
+		// in-flight envelopes reach the limit, next message is not admitted
+		in <- 1
+		in <- 2
+		a1, a2 := <-acks, <-acks
+		in <- 3
+
+		select {
+		case <-acks:
+			t.Error("message is admitted")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		// acknowledgement out of order does not advance the watermark
+		a2.Done()
+		select {
+		case <-acks:
+			t.Error("message is admitted")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		a1.Done()
+		(<-acks).Done()
+		it.Then(t).Should(
+			it.Equal(<-commits, 1),
+			it.Equal(<-commits, 2),
+			it.Equal(<-commits, 3),
+		)
+
+		close()
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := pipe.Track(ctx, pipe.Seq(1, 2, 3))
+
+		<-acks
+		close()
+
+		it.Then(t).Should(
+			it.Equal(len(pipe.ToSeq(commits)), 0),
+		)
+	})
+}
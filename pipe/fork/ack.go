@@ -0,0 +1,92 @@
+//
+// Copyright (C) 2022 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package fork
+
+import (
+	"context"
+
+	"github.com/fogfish/golem/pipe/v2"
+)
+
+// Ack is an acknowledgement tracking envelope of value T, see pipe.Ack.
+type Ack[T any] = pipe.Ack[T]
+
+// Track wraps messages of the input channel into acknowledgement tracking
+// envelopes, see pipe.Track for details.
+func Track[T any](ctx context.Context, in <-chan T) (<-chan Ack[T], <-chan T) {
+	return pipe.Track(ctx, in)
+}
+
+// AckF lifts morphism 𝑓: A ⟼ B over acknowledgement tracking envelopes,
+// see pipe.AckF for details.
+func AckF[A, B any](f F[A, B]) F[Ack[A], Ack[B]] {
+	return ackf[Ack[A], Ack[B]]{f: f, p: pipe.AckF(f.pipef())}
+}
+
+// AckFilter lifts predicate over acknowledgement tracking envelopes for Filter
+// and FilterE, see pipe.AckFilter for details.
+func AckFilter[A any](f F[A, bool]) F[Ack[A], bool] {
+	return ackf[Ack[A], bool]{f: f, p: pipe.AckFilter(f.pipef())}
+}
+
+// semantic of morphism failures
+type semantic interface {
+	errch(cap int) chan error
+	catch(context.Context, error, chan<- error) bool
+}
+
+// Note: envelopes are handled by pipe, the morphism f defines error semantic
+type ackf[A, B any] struct {
+	f semantic
+	p pipe.F[A, B]
+}
+
+func (f ackf[A, B]) Apply(a A) (B, error) {
+	return f.p.Apply(a)
+}
+
+//lint:ignore U1000 false positive
+func (f ackf[A, B]) errch(cap int) chan error {
+	return f.f.errch(cap)
+}
+
+//lint:ignore U1000 false positive
+func (f ackf[A, B]) catch(ctx context.Context, err error, exx chan<- error) bool {
+	return f.f.catch(ctx, err, exx)
+}
+
+//lint:ignore U1000 false positive
+func (f ackf[A, B]) pipef() pipe.F[A, B] {
+	return f.p
+}
+
+// AckFF lifts functor morphism 𝓕: A ⟼ B over acknowledgement tracking envelopes,
+// see pipe.AckFF for details.
+func AckFF[A, B any](f FF[A, B]) FF[Ack[A], Ack[B]] {
+	return ackff[Ack[A], Ack[B]]{f: f, p: pipe.AckFF(pipe.LiftF(f.Apply))}
+}
+
+type ackff[A, B any] struct {
+	f semantic
+	p pipe.FF[A, B]
+}
+
+func (f ackff[A, B]) Apply(ctx context.Context, a A, out chan<- B) error {
+	return f.p.Apply(ctx, a, out)
+}
+
+//lint:ignore U1000 false positive
+func (f ackff[A, B]) errch(cap int) chan error {
+	return f.f.errch(cap)
+}
+
+//lint:ignore U1000 false positive
+func (f ackff[A, B]) catch(ctx context.Context, err error, exx chan<- error) bool {
+	return f.f.catch(ctx, err, exx)
+}
@@ -0,0 +1,87 @@
+//
+// Copyright (C) 2022 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package fork_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fogfish/golem/pipe/v2/fork"
+	"github.com/fogfish/it/v2"
+)
+
+func TestTrack(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := fork.Track(ctx, fork.Seq(1, 2, 3, 4, 5))
+		out := fork.StdErr(fork.Map(ctx, par, acks,
+			fork.AckF(fork.Pure(func(x int) int { return x * 10 })),
+		))
+
+		vals := make([]int, 0)
+		for x := range out {
+			vals = append(vals, x.Value)
+			x.Done()
+		}
+
+		it.Then(t).Should(
+			it.Seq(vals).Contain().AllOf(10, 20, 30, 40, 50),
+			it.Seq(fork.ToSeq(commits)).Equal(1, 2, 3, 4, 5),
+		)
+
+		close()
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := fork.Track(ctx, fork.Seq(1, 2, 3, 4))
+		out := fork.Filter(ctx, par, acks,
+			fork.AckFilter(fork.Pure(func(x int) bool { return x%2 == 0 })),
+		)
+
+		vals := make([]int, 0)
+		for x := range out {
+			vals = append(vals, x.Value)
+			x.Done()
+		}
+
+		it.Then(t).Should(
+			it.Seq(vals).Contain().AllOf(2, 4),
+			it.Seq(fork.ToSeq(commits)).Equal(1, 2, 3, 4),
+		)
+
+		close()
+	})
+
+	t.Run("FMap", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+		acks, commits := fork.Track(ctx, fork.Seq(1, 2, 3))
+		out := fork.StdErr(fork.FMap(ctx, par, acks,
+			fork.AckFF(fork.LiftF(func(ctx context.Context, x int, ch chan<- int) error {
+				for i := 0; i < x; i++ {
+					ch <- x
+				}
+				return nil
+			})),
+		))
+
+		vals := make([]int, 0)
+		for x := range out {
+			vals = append(vals, x.Value)
+			x.Done()
+		}
+
+		it.Then(t).Should(
+			it.Equal(len(vals), 6),
+			it.Seq(fork.ToSeq(commits)).Equal(1, 2, 3),
+		)
+
+		close()
+	})
+}
@@ -12,6 +12,7 @@ package fork
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -58,6 +59,56 @@ func Filter[A any](ctx context.Context, par int, in <-chan A, f F[A, bool]) <-ch
 	return out
 }
 
+// FilterE returns a newly-allocated channel that contains only those elements x
+// of the input channel for which predicate is true. Unlike Filter, failures of
+// predicate are emitted to the error channel, following Lift/Try semantic.
+func FilterE[A any](ctx context.Context, par int, in <-chan A, f F[A, bool]) (<-chan A, <-chan error) {
+	var wg sync.WaitGroup
+	out := make(chan A, par)
+	exx := make(chan error, par)
+
+	pf := func() {
+		defer wg.Done()
+
+		var (
+			a    A
+			take bool
+			err  error
+		)
+
+		for a = range in {
+			take, err = f.Apply(a)
+			if err != nil {
+				if !f.catch(ctx, err, exx) {
+					return
+				}
+				continue
+			}
+
+			if take {
+				select {
+				case out <- a:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+
+	wg.Add(par)
+	for i := 1; i <= par; i++ {
+		go pf()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+		close(exx)
+	}()
+
+	return out, exx
+}
+
 // ForEach applies function for each message in the channel
 func ForEach[A any](ctx context.Context, par int, in <-chan A, f F[A, A]) <-chan struct{} {
 	var wg sync.WaitGroup
@@ -293,6 +344,287 @@ func Partition[A any](ctx context.Context, par int, in <-chan A, f F[A, bool]) (
 	return lout, rout
 }
 
+// PartitionE channel into two channels according to predicate. Unlike Partition,
+// failures of predicate are emitted to the error channel, following Lift/Try semantic.
+func PartitionE[A any](ctx context.Context, par int, in <-chan A, f F[A, bool]) (<-chan A, <-chan A, <-chan error) {
+	var wg sync.WaitGroup
+	lout := make(chan A, par)
+	rout := make(chan A, par)
+	exx := make(chan error, par)
+
+	pf := func() {
+		defer wg.Done()
+
+		var (
+			a   A
+			x   bool
+			err error
+		)
+
+		for a = range in {
+			x, err = f.Apply(a)
+			if err != nil {
+				if !f.catch(ctx, err, exx) {
+					return
+				}
+				continue
+			}
+
+			sel := rout
+			if x {
+				sel = lout
+			}
+
+			select {
+			case sel <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	wg.Add(par)
+	for i := 1; i <= par; i++ {
+		go pf()
+	}
+
+	go func() {
+		wg.Wait()
+		close(lout)
+		close(rout)
+		close(exx)
+	}()
+
+	return lout, rout, exx
+}
+
+// Route channel into n channels according to classifier. The element is
+// routed to the channel with index returned by classifier. Elements classified
+// outside of range [0, n) or failed by classifier are routed to the default channel,
+// use RouteE to observe failures. With n = 0, all elements are routed to
+// the default channel. It panics if n is negative.
+func Route[A any](ctx context.Context, par int, in <-chan A, n int, f F[A, int]) ([]<-chan A, <-chan A) {
+	if n < 0 {
+		panic(fmt.Errorf("invalid number of channels: %d", n))
+	}
+
+	var wg sync.WaitGroup
+	outs := make([]chan A, n)
+	for i := range outs {
+		outs[i] = make(chan A, par)
+	}
+	dout := make(chan A, par)
+
+	pf := func() {
+		defer wg.Done()
+
+		sel := func(x int, err error) chan<- A {
+			if err == nil && x >= 0 && x < n {
+				return outs[x]
+			}
+			return dout
+		}
+
+		var a A
+		for a = range in {
+			select {
+			case sel(f.Apply(a)) <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	wg.Add(par)
+	for i := 1; i <= par; i++ {
+		go pf()
+	}
+
+	go func() {
+		wg.Wait()
+		for _, out := range outs {
+			close(out)
+		}
+		close(dout)
+	}()
+
+	eg := make([]<-chan A, n)
+	for i, out := range outs {
+		eg[i] = out
+	}
+
+	return eg, dout
+}
+
+// RouteE channel into n channels according to classifier. Unlike Route,
+// failures of classifier are emitted to the error channel, following Lift/Try
+// semantic. Elements classified outside of range [0, n) are routed to
+// the default channel. It panics if n is negative.
+func RouteE[A any](ctx context.Context, par int, in <-chan A, n int, f F[A, int]) ([]<-chan A, <-chan A, <-chan error) {
+	if n < 0 {
+		panic(fmt.Errorf("invalid number of channels: %d", n))
+	}
+
+	outs := make([]chan A, n)
+	for i := range outs {
+		outs[i] = make(chan A, par)
+	}
+	dout := make(chan A, par)
+	exx := make(chan error, par)
+
+	sel := func(x int) chan A {
+		if x >= 0 && x < n {
+			return outs[x]
+		}
+		return dout
+	}
+
+	eg := make([]<-chan A, n)
+	for i, out := range outs {
+		eg[i] = out
+	}
+
+	done := func() {
+		for _, out := range outs {
+			close(out)
+		}
+		close(dout)
+		close(exx)
+	}
+
+	route(ctx, par, in, f, sel, exx, done)
+	return eg, dout, exx
+}
+
+// RouteBy channel into channels identified by keys according to classifier.
+// Elements classified with unknown key or failed by classifier are routed to
+// the default channel, use RouteByE to observe failures.
+func RouteBy[A any, K comparable](ctx context.Context, par int, in <-chan A, keys []K, f F[A, K]) (map[K]<-chan A, <-chan A) {
+	var wg sync.WaitGroup
+	outs := make(map[K]chan A, len(keys))
+	for _, k := range keys {
+		outs[k] = make(chan A, par)
+	}
+	dout := make(chan A, par)
+
+	pf := func() {
+		defer wg.Done()
+
+		sel := func(k K, err error) chan<- A {
+			if out, has := outs[k]; has && err == nil {
+				return out
+			}
+			return dout
+		}
+
+		var a A
+		for a = range in {
+			select {
+			case sel(f.Apply(a)) <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	wg.Add(par)
+	for i := 1; i <= par; i++ {
+		go pf()
+	}
+
+	go func() {
+		wg.Wait()
+		for _, out := range outs {
+			close(out)
+		}
+		close(dout)
+	}()
+
+	eg := make(map[K]<-chan A, len(outs))
+	for k, out := range outs {
+		eg[k] = out
+	}
+
+	return eg, dout
+}
+
+// RouteByE channel into channels identified by keys according to classifier.
+// Unlike RouteBy, failures of classifier are emitted to the error channel,
+// following Lift/Try semantic. Elements classified with unknown key are routed
+// to the default channel.
+func RouteByE[A any, K comparable](ctx context.Context, par int, in <-chan A, keys []K, f F[A, K]) (map[K]<-chan A, <-chan A, <-chan error) {
+	outs := make(map[K]chan A, len(keys))
+	for _, k := range keys {
+		outs[k] = make(chan A, par)
+	}
+	dout := make(chan A, par)
+	exx := make(chan error, par)
+
+	sel := func(k K) chan A {
+		if out, has := outs[k]; has {
+			return out
+		}
+		return dout
+	}
+
+	eg := make(map[K]<-chan A, len(outs))
+	for k, out := range outs {
+		eg[k] = out
+	}
+
+	done := func() {
+		for _, out := range outs {
+			close(out)
+		}
+		close(dout)
+		close(exx)
+	}
+
+	route(ctx, par, in, f, sel, exx, done)
+	return eg, dout, exx
+}
+
+// routes elements of channel using classifier, failures are emitted to exx
+func route[A any, K comparable](ctx context.Context, par int, in <-chan A, f F[A, K], sel func(K) chan A, exx chan error, done func()) {
+	var wg sync.WaitGroup
+
+	pf := func() {
+		defer wg.Done()
+
+		var (
+			a   A
+			k   K
+			err error
+		)
+
+		for a = range in {
+			k, err = f.Apply(a)
+			if err != nil {
+				if !f.catch(ctx, err, exx) {
+					return
+				}
+				continue
+			}
+
+			select {
+			case sel(k) <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	wg.Add(par)
+	for i := 1; i <= par; i++ {
+		go pf()
+	}
+
+	go func() {
+		wg.Wait()
+		done()
+	}()
+}
+
 // Unfold is the fundamental recursive constructor, it applies a function to
 // each previous seed element in turn to determine the next element.
 func Unfold[A any](ctx context.Context, cap int, seed A, f F[A, A]) (<-chan A, <-chan error) {
@@ -305,6 +637,19 @@ func Join[A any](ctx context.Context, in ...<-chan A) <-chan A {
 	return pipe.Join(ctx, in...)
 }
 
+// JoinPriority concatenate channels, elements of higher priority channels
+// (the first one has the highest) are emitted before lower priority ones.
+// See pipe.JoinPriority for details about starvation limit.
+func JoinPriority[A any](ctx context.Context, starvation int, in ...<-chan A) <-chan A {
+	return pipe.JoinPriority(ctx, starvation, in...)
+}
+
+// JoinWeighted concatenate channels using weighted round-robin fair queuing.
+// See pipe.JoinWeighted for details.
+func JoinWeighted[A any](ctx context.Context, weights []int, in ...<-chan A) <-chan A {
+	return pipe.JoinWeighted(ctx, weights, in...)
+}
+
 // returns a newly-allocated channel containing the first n elements of the input channel.
 func Take[A any](ctx context.Context, in <-chan A, n int) <-chan A {
 	return pipe.Take(ctx, in, n)
@@ -316,6 +661,13 @@ func TakeWhile[A any](ctx context.Context, in <-chan A, f F[A, bool]) <-chan A {
 	return pipe.TakeWhile(ctx, in, f.pipef())
 }
 
+// TakeWhileE returns a newly-allocated channel that contains those elements from
+// channel while predicate returns true. Unlike TakeWhile, failures of predicate
+// are emitted to the error channel, following Lift/Try semantic.
+func TakeWhileE[A any](ctx context.Context, in <-chan A, f F[A, bool]) (<-chan A, <-chan error) {
+	return pipe.TakeWhileE(ctx, in, f.pipef())
+}
+
 // Throttling the channel to ops per time interval
 func Throttling[A any](ctx context.Context, in <-chan A, ops int, interval time.Duration) <-chan A {
 	return pipe.Throttling(ctx, in, ops, interval)
@@ -80,6 +80,37 @@ func TestFilter(t *testing.T) {
 	close()
 }
 
+func TestFilterE(t *testing.T) {
+	fun := fork.Try(func(x int) (bool, error) {
+		if x%2 == 0 {
+			return false, fmt.Errorf("even")
+		}
+		return true, nil
+	})
+
+	ctx, close := context.WithCancel(context.Background())
+	seq := fork.Seq(1, 2, 3, 4, 5)
+	out, exx := fork.FilterE(ctx, par, seq, fun)
+
+	var errs []error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs = fork.ToSeq(exx)
+	}()
+
+	vals := fork.ToSeq(out)
+	wg.Wait()
+
+	it.Then(t).Should(
+		it.Seq(vals).Contain().AllOf(1, 3, 5),
+		it.Equal(len(errs), 2),
+	)
+
+	close()
+}
+
 func TestForEach(t *testing.T) {
 	var m sync.Mutex
 	n := 0
@@ -244,6 +275,134 @@ func TestPartition(t *testing.T) {
 	close()
 }
 
+func TestPartitionE(t *testing.T) {
+	fun := fork.Try(func(x int) (bool, error) {
+		if x == 3 {
+			return false, fmt.Errorf("three")
+		}
+		return x%2 == 1, nil
+	})
+
+	ctx, close := context.WithCancel(context.Background())
+	seq := fork.Seq(1, 2, 3, 4, 5)
+	lo, ro, exx := fork.PartitionE(ctx, par, seq, fun)
+
+	it.Then(t).Should(
+		it.Seq(fork.ToSeq(lo)).Contain().AllOf(1, 5),
+		it.Seq(fork.ToSeq(ro)).Contain().AllOf(2, 4),
+		it.Seq(fork.ToSeq(exx)).Equal(fmt.Errorf("three")),
+	)
+
+	close()
+}
+
+func TestRoute(t *testing.T) {
+	t.Run("Route", func(t *testing.T) {
+		fun := fork.Pure(func(x int) int { return x % 4 })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := fork.Seq(1, 2, 3, 4, 5, 6, 7)
+		outs, def := fork.Route(ctx, par, seq, 3, fun)
+
+		it.Then(t).Should(
+			it.Equal(len(outs), 3),
+			it.Seq(fork.ToSeq(outs[0])).Contain().AllOf(4),
+			it.Seq(fork.ToSeq(outs[1])).Contain().AllOf(1, 5),
+			it.Seq(fork.ToSeq(outs[2])).Contain().AllOf(2, 6),
+			it.Seq(fork.ToSeq(def)).Contain().AllOf(3, 7),
+		)
+
+		close()
+	})
+
+	t.Run("OutOfRange", func(t *testing.T) {
+		fun := fork.Pure(func(x int) int { return x - 2 })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := fork.Seq(1, 2, 3, 4, 5)
+		outs, def := fork.Route(ctx, par, seq, 2, fun)
+
+		it.Then(t).Should(
+			it.Seq(fork.ToSeq(outs[0])).Equal(2),
+			it.Seq(fork.ToSeq(outs[1])).Equal(3),
+			it.Seq(fork.ToSeq(def)).Contain().AllOf(1, 4, 5),
+		)
+
+		close()
+	})
+
+	t.Run("Negative", func(t *testing.T) {
+		fun := fork.Pure(func(x int) int { return 0 })
+
+		it.Then(t).Should(
+			it.Fail(func() { fork.Route(context.Background(), par, fork.Seq(1), -1, fun) }).
+				Contain("invalid number of channels"),
+		)
+	})
+
+	t.Run("RouteBy", func(t *testing.T) {
+		fun := fork.Pure(func(x string) string { return x[:1] })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := fork.Seq("a1", "b1", "c1", "a2", "b2")
+		outs, def := fork.RouteBy(ctx, par, seq, []string{"a", "b"}, fun)
+
+		it.Then(t).Should(
+			it.Seq(fork.ToSeq(outs["a"])).Contain().AllOf("a1", "a2"),
+			it.Seq(fork.ToSeq(outs["b"])).Contain().AllOf("b1", "b2"),
+			it.Seq(fork.ToSeq(def)).Contain().AllOf("c1"),
+		)
+
+		close()
+	})
+}
+
+func TestRouteE(t *testing.T) {
+	t.Run("RouteE", func(t *testing.T) {
+		fun := fork.Try(func(x int) (int, error) {
+			if x == 3 {
+				return 0, fmt.Errorf("three")
+			}
+			return x % 2, nil
+		})
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := fork.Seq(1, 2, 3, 4, 5, -1)
+		outs, def, exx := fork.RouteE(ctx, par, seq, 2, fun)
+
+		it.Then(t).Should(
+			it.Seq(fork.ToSeq(outs[0])).Contain().AllOf(2, 4),
+			it.Seq(fork.ToSeq(outs[1])).Contain().AllOf(1, 5),
+			it.Seq(fork.ToSeq(def)).Contain().AllOf(-1),
+			it.Seq(fork.ToSeq(exx)).Equal(fmt.Errorf("three")),
+		)
+
+		close()
+	})
+
+	t.Run("RouteByE", func(t *testing.T) {
+		fun := fork.Try(func(x string) (string, error) {
+			if x == "" {
+				return "", fmt.Errorf("empty")
+			}
+			return x[:1], nil
+		})
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := fork.Seq("a1", "b1", "", "c1", "a2")
+		outs, def, exx := fork.RouteByE(ctx, par, seq, []string{"a", "b"}, fun)
+
+		it.Then(t).Should(
+			it.Seq(fork.ToSeq(outs["a"])).Contain().AllOf("a1", "a2"),
+			it.Seq(fork.ToSeq(outs["b"])).Contain().AllOf("b1"),
+			it.Seq(fork.ToSeq(def)).Contain().AllOf("c1"),
+			it.Seq(fork.ToSeq(exx)).Equal(fmt.Errorf("empty")),
+		)
+
+		close()
+	})
+}
+
 func TestUnfold(t *testing.T) {
 	fun := fork.Pure(func(x int) int { return x + 1 })
 
@@ -277,6 +436,36 @@ func TestJoin(t *testing.T) {
 	close()
 }
 
+func TestJoinPriority(t *testing.T) {
+	ctx, close := context.WithCancel(context.Background())
+
+	a := fork.Seq(1, 2, 3)
+	b := fork.Seq(4, 5, 6)
+
+	out := fork.JoinPriority(ctx, 0, b, a)
+
+	it.Then(t).Should(
+		it.Seq(fork.ToSeq(out)).Equal(4, 5, 6, 1, 2, 3),
+	)
+
+	close()
+}
+
+func TestJoinWeighted(t *testing.T) {
+	ctx, close := context.WithCancel(context.Background())
+
+	a := fork.Seq(1, 2, 3, 4)
+	b := fork.Seq(5, 6)
+
+	out := fork.JoinWeighted(ctx, []int{2, 1}, a, b)
+
+	it.Then(t).Should(
+		it.Seq(fork.ToSeq(out)).Equal(1, 2, 5, 3, 4, 6),
+	)
+
+	close()
+}
+
 func TestTake(t *testing.T) {
 	ctx, close := context.WithCancel(context.Background())
 	seq := fork.Seq(1, 2, 3, 4, 5, 6)
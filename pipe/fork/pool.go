@@ -0,0 +1,227 @@
+//
+// Copyright (C) 2022 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package fork
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by futures submitted to closed pool.
+var ErrClosed = errors.New("pool is closed")
+
+// Future is a placeholder of the result of computation submitted to the pool.
+type Future[B any] struct{ *promise[B] }
+
+type promise[B any] struct {
+	done chan struct{}
+	val  B
+	err  error
+}
+
+func newFuture[B any]() Future[B] {
+	return Future[B]{&promise[B]{done: make(chan struct{})}}
+}
+
+func (f Future[B]) resolve(val B, err error) {
+	f.val, f.err = val, err
+	close(f.done)
+}
+
+// Await blocks until the result of computation is available or context is cancelled.
+func (f Future[B]) Await(ctx context.Context) (B, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		return *new(B), ctx.Err()
+	}
+}
+
+// Pool is a long-living set of parallel workers applying the morphism 𝑓: A ⟼ B
+// to submitted values. It is a request/response counterpart of Map for call
+// sites that need bounded concurrency without channel pipeline.
+//
+// Pool follows Lift/Try semantic of morphism, the failure is returned to
+// the caller and emitted to the error channel as Map does. The failure of
+// lifted morphism aborts the pool, all pending and following computations
+// fail with the same error. The failure of tried morphism continues the
+// computation. Failures are emitted to the error channel on best-effort
+// basis, they are dropped if the channel is not consumed.
+type Pool[A, B any] struct {
+	f       F[A, B]
+	backoff []time.Duration
+	queue   chan task[A, B]
+	exx     chan error
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+	pending sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	abort   sync.Once
+	failure error
+}
+
+type task[A, B any] struct {
+	ctx context.Context
+	val A
+	eg  Future[B]
+}
+
+// NewPool creates the pool of par workers for morphism 𝑓: A ⟼ B. The pool
+// queues up to cap submitted values, Submit blocks if the queue is full.
+// The failed computation is retried after each backoff delay, the failure of
+// last attempt is handled according to Lift/Try semantic of morphism.
+//
+// The error channel is closed when pool is closed.
+func NewPool[A, B any](ctx context.Context, par int, cap int, f F[A, B], backoff ...time.Duration) (*Pool[A, B], <-chan error) {
+	pctx, cancel := context.WithCancelCause(ctx)
+
+	pool := &Pool[A, B]{
+		f:       f,
+		backoff: backoff,
+		queue:   make(chan task[A, B], cap),
+		exx:     make(chan error, par),
+		ctx:     pctx,
+		cancel:  cancel,
+	}
+
+	pool.wg.Add(par)
+	for i := 1; i <= par; i++ {
+		go pool.worker()
+	}
+
+	return pool, pool.exx
+}
+
+func (pool *Pool[A, B]) worker() {
+	defer pool.wg.Done()
+
+	for t := range pool.queue {
+		pool.exec(t)
+	}
+}
+
+func (pool *Pool[A, B]) exec(t task[A, B]) {
+	if err := context.Cause(pool.ctx); err != nil {
+		t.eg.resolve(*new(B), err)
+		return
+	}
+
+	if err := t.ctx.Err(); err != nil {
+		t.eg.resolve(*new(B), err)
+		return
+	}
+
+	val, err := pool.apply(t)
+	if err != nil {
+		if !pool.caught(err) && pool.ctx.Err() == nil {
+			pool.abort.Do(func() {
+				pool.failure = err
+				pool.cancel(err)
+			})
+		}
+		t.eg.resolve(*new(B), err)
+		return
+	}
+
+	t.eg.resolve(val, nil)
+}
+
+// caught failure of tried morphism. The failure is emitted to the error
+// channel on best-effort basis, it is dropped if the channel is not consumed.
+// Workers are never blocked by the error channel, the future receives
+// the failure anyway.
+func (pool *Pool[A, B]) caught(err error) bool {
+	ch := make(chan error, 1)
+	ok := pool.f.catch(pool.ctx, err, ch)
+
+	select {
+	case pool.exx <- err:
+	default:
+	}
+
+	return ok
+}
+
+// apply morphism, retry it after each backoff delay unless context is done
+func (pool *Pool[A, B]) apply(t task[A, B]) (B, error) {
+	val, err := pool.f.Apply(t.val)
+	for _, delay := range pool.backoff {
+		if err == nil {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-t.ctx.Done():
+			timer.Stop()
+			return *new(B), err
+		case <-pool.ctx.Done():
+			timer.Stop()
+			return *new(B), err
+		}
+
+		val, err = pool.f.Apply(t.val)
+	}
+
+	return val, err
+}
+
+// Submit value to the pool, returns the future of computation result.
+func (pool *Pool[A, B]) Submit(ctx context.Context, a A) Future[B] {
+	eg := newFuture[B]()
+
+	pool.mu.RLock()
+	if pool.closed {
+		pool.mu.RUnlock()
+		eg.resolve(*new(B), ErrClosed)
+		return eg
+	}
+	pool.pending.Add(1)
+	pool.mu.RUnlock()
+
+	// Note: the lock is not held while blocked on full queue,
+	//       Close waits for pending submits before closing the queue.
+	defer pool.pending.Done()
+
+	select {
+	case pool.queue <- task[A, B]{ctx: ctx, val: a, eg: eg}:
+	case <-ctx.Done():
+		eg.resolve(*new(B), ctx.Err())
+	}
+
+	return eg
+}
+
+// Close the pool gracefully. It stops accepting new values and waits until all
+// queued computations are completed. It returns the failure of lifted morphism
+// that aborted the pool.
+func (pool *Pool[A, B]) Close() error {
+	pool.mu.Lock()
+	if pool.closed {
+		pool.mu.Unlock()
+		pool.wg.Wait()
+		return pool.failure
+	}
+	pool.closed = true
+	pool.mu.Unlock()
+
+	pool.pending.Wait()
+	close(pool.queue)
+	pool.wg.Wait()
+	close(pool.exx)
+	pool.cancel(nil)
+
+	return pool.failure
+}
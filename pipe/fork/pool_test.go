@@ -0,0 +1,220 @@
+//
+// Copyright (C) 2022 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package fork_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fogfish/golem/pipe/v2/fork"
+	"github.com/fogfish/it/v2"
+)
+
+func TestPool(t *testing.T) {
+	t.Run("Submit", func(t *testing.T) {
+		pool, _ := fork.NewPool(context.Background(), par, 0, fork.Pure(strconv.Itoa))
+
+		futures := make([]fork.Future[string], 0)
+		for i := 1; i <= 5; i++ {
+			futures = append(futures, pool.Submit(context.Background(), i))
+		}
+
+		for i, f := range futures {
+			val, err := f.Await(context.Background())
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(val, strconv.Itoa(i+1)),
+			)
+		}
+
+		it.Then(t).Should(
+			it.Nil(pool.Close()),
+		)
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		fun := fork.Lift(func(x int) (int, error) { return 0, fmt.Errorf("fail") })
+		pool, exx := fork.NewPool(context.Background(), 1, 0, fun)
+
+		_, err := pool.Submit(context.Background(), 1).Await(context.Background())
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("fail"),
+			it.Fail(func() error { return <-exx }).Contain("fail"),
+		)
+
+		_, err = pool.Submit(context.Background(), 2).Await(context.Background())
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("fail"),
+			it.Fail(pool.Close).Contain("fail"),
+		)
+	})
+
+	t.Run("Try", func(t *testing.T) {
+		fun := fork.Try(func(x int) (int, error) {
+			if x%2 == 1 {
+				return 0, fmt.Errorf("odd")
+			}
+			return x, nil
+		})
+		pool, exx := fork.NewPool(context.Background(), 1, 0, fun)
+
+		_, err1 := pool.Submit(context.Background(), 1).Await(context.Background())
+		val2, err2 := pool.Submit(context.Background(), 2).Await(context.Background())
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err1 }).Contain("odd"),
+			it.Fail(func() error { return <-exx }).Contain("odd"),
+			it.Nil(err2),
+			it.Equal(val2, 2),
+			it.Nil(pool.Close()),
+		)
+	})
+
+	t.Run("TryNotConsumed", func(t *testing.T) {
+		fun := fork.Try(func(x int) (int, error) { return 0, fmt.Errorf("fail") })
+		pool, _ := fork.NewPool(context.Background(), 1, 0, fun)
+
+		for i := 0; i < 10; i++ {
+			_, err := pool.Submit(context.Background(), i).Await(context.Background())
+			it.Then(t).Should(
+				it.Fail(func() error { return err }).Contain("fail"),
+			)
+		}
+
+		it.Then(t).Should(
+			it.Nil(pool.Close()),
+		)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		var n atomic.Int32
+		fun := fork.Pure(func(x int) int {
+			time.Sleep(10 * time.Millisecond)
+			n.Add(1)
+			return x
+		})
+		pool, _ := fork.NewPool(context.Background(), 2, 10, fun)
+
+		futures := make([]fork.Future[int], 0)
+		for i := 1; i <= 10; i++ {
+			futures = append(futures, pool.Submit(context.Background(), i))
+		}
+
+		it.Then(t).Should(
+			it.Nil(pool.Close()),
+			it.Equal(n.Load(), 10),
+		)
+
+		for _, f := range futures {
+			_, err := f.Await(context.Background())
+			it.Then(t).Should(it.Nil(err))
+		}
+
+		_, err := pool.Submit(context.Background(), 11).Await(context.Background())
+		it.Then(t).Should(
+			it.Equiv(err, fork.ErrClosed),
+		)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		var n atomic.Int32
+		fun := fork.Lift(func(x int) (int, error) {
+			if n.Add(1) < 3 {
+				return 0, fmt.Errorf("fail")
+			}
+			return x, nil
+		})
+		pool, _ := fork.NewPool(context.Background(), 1, 0, fun, time.Millisecond, time.Millisecond)
+
+		val, err := pool.Submit(context.Background(), 1).Await(context.Background())
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(val, 1),
+			it.Equal(n.Load(), 3),
+			it.Nil(pool.Close()),
+		)
+	})
+
+	t.Run("RetryCancel", func(t *testing.T) {
+		fun := fork.Try(func(x int) (int, error) { return 0, fmt.Errorf("fail") })
+		pool, exx := fork.NewPool(context.Background(), 1, 0, fun, time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		eg := pool.Submit(ctx, 1)
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		_, err := eg.Await(context.Background())
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("fail"),
+			it.Fail(func() error { return <-exx }).Contain("fail"),
+			it.Nil(pool.Close()),
+		)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		fail := fmt.Errorf("fail: %w", context.Canceled)
+		fun := fork.Lift(func(x int) (int, error) { return 0, fail })
+		pool, _ := fork.NewPool(context.Background(), 1, 0, fun)
+
+		pool.Submit(context.Background(), 1).Await(context.Background())
+		it.Then(t).Should(
+			it.Equiv(pool.Close(), fail),
+		)
+	})
+
+	t.Run("CloseBlocked", func(t *testing.T) {
+		gate := make(chan struct{})
+		fun := fork.Pure(func(x int) int { <-gate; return x })
+		pool, _ := fork.NewPool(context.Background(), 1, 0, fun)
+
+		futures := make(chan fork.Future[int], 3)
+		for i := 1; i <= 3; i++ {
+			go func(i int) { futures <- pool.Submit(context.Background(), i) }(i)
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		done := make(chan error)
+		go func() { done <- pool.Close() }()
+		time.Sleep(10 * time.Millisecond)
+		close(gate)
+
+		it.Then(t).Should(
+			it.Nil(<-done),
+		)
+
+		for i := 1; i <= 3; i++ {
+			_, err := (<-futures).Await(context.Background())
+			if err != nil {
+				it.Then(t).Should(it.Equiv(err, fork.ErrClosed))
+			}
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		fun := fork.Pure(func(x int) int {
+			time.Sleep(100 * time.Millisecond)
+			return x
+		})
+		pool, _ := fork.NewPool(context.Background(), 1, 0, fun)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := pool.Submit(context.Background(), 1).Await(ctx)
+		it.Then(t).Should(
+			it.Equiv(err, context.DeadlineExceeded),
+			it.Nil(pool.Close()),
+		)
+	})
+}
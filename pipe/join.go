@@ -0,0 +1,214 @@
+//
+// Copyright (C) 2022 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package pipe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// JoinPriority concatenate channels, returns newly-allocated channel composed of
+// elements copied from input channels. Unlike Join, input channels are ordered
+// by priority, the first channel has the highest one. Elements of higher
+// priority channels are always emitted before elements of lower priority ones.
+//
+// Strict priority starves lower priority channels if higher priority channels
+// are never empty. The starvation limit is the number of consecutive elements
+// emitted before lower priority channels are given a turn. Use 0 to disable it.
+func JoinPriority[A any](ctx context.Context, starvation int, in ...<-chan A) <-chan A {
+	out := make(chan A, len(in))
+	seq := make([]<-chan A, len(in))
+	copy(seq, in)
+
+	go func() {
+		defer close(out)
+
+		var (
+			x      A
+			i      int
+			ok     bool
+			streak int
+			turn   = 1
+		)
+
+		for {
+			if starvation > 0 && streak >= starvation && len(seq) > 1 {
+				// starvation turn, lower priority channels are polled first
+				x, i, ok = poll(seq, turn)
+				streak = 0
+				if ok && i != 0 {
+					turn = i + 1
+					if turn == len(seq) {
+						turn = 1
+					}
+				}
+			} else {
+				x, i, ok = poll(seq, 0)
+				streak++
+			}
+
+			if !ok {
+				// nothing is ready, no one is starving
+				x, _, ok = wait(ctx, seq)
+				if !ok {
+					return
+				}
+				streak = 0
+			}
+
+			select {
+			case out <- x:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// JoinWeighted concatenate channels, returns newly-allocated channel composed of
+// elements copied from input channels. Channels are served using weighted
+// round-robin: each round emits up to weights[i] elements from channel in[i].
+// Weights below 1 are treated as 1.
+//
+// The join is work-conserving, idle channels do not block others. It is also
+// starvation-free, any ready channel waits at most for the sum of weights of
+// other channels before being served.
+func JoinWeighted[A any](ctx context.Context, weights []int, in ...<-chan A) <-chan A {
+	if len(weights) != len(in) {
+		panic(fmt.Errorf("invalid weights: %d weights given for %d channels", len(weights), len(in)))
+	}
+
+	out := make(chan A, len(in))
+	seq := make([]<-chan A, len(in))
+	copy(seq, in)
+
+	wts := make([]int, len(weights))
+	for i, w := range weights {
+		wts[i] = max(w, 1)
+	}
+
+	emit := func(x A) bool {
+		select {
+		case out <- x:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(out)
+
+		var (
+			x  A
+			ok bool
+		)
+
+		for {
+			served := false
+			for i := range seq {
+				for k := 0; k < wts[i]; k++ {
+					if x, ok = pollAt(seq, i); !ok {
+						break
+					}
+					if !emit(x) {
+						return
+					}
+					served = true
+				}
+			}
+
+			if !served {
+				if x, _, ok = wait(ctx, seq); !ok {
+					return
+				}
+				if !emit(x) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// non-blocking receive from the first ready channel, starting with seq[start].
+// Closed channels are removed from the sequence.
+func poll[A any](seq []<-chan A, start int) (A, int, bool) {
+	for k := 0; k < len(seq); k++ {
+		i := (start + k) % len(seq)
+		if x, ok := pollAt(seq, i); ok {
+			return x, i, true
+		}
+	}
+
+	return *new(A), -1, false
+}
+
+// non-blocking receive from channel seq[i].
+func pollAt[A any](seq []<-chan A, i int) (A, bool) {
+	if seq[i] == nil {
+		return *new(A), false
+	}
+
+	select {
+	case x, ok := <-seq[i]:
+		if !ok {
+			seq[i] = nil
+			return *new(A), false
+		}
+		return x, true
+	default:
+		return *new(A), false
+	}
+}
+
+// blocking receive from any of channels. It returns false when context is
+// cancelled or all channels are closed.
+func wait[A any](ctx context.Context, seq []<-chan A) (A, int, bool) {
+	cases := make([]reflect.SelectCase, len(seq)+1)
+	cases[0] = reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	}
+
+	for {
+		open := 0
+		for i, ch := range seq {
+			if ch == nil {
+				// case with zero channel value is ignored by select
+				cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv}
+				continue
+			}
+			cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
+			open++
+		}
+
+		if open == 0 {
+			return *new(A), -1, false
+		}
+
+		chosen, x, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return *new(A), -1, false
+		}
+
+		if !ok {
+			seq[chosen-1] = nil
+			continue
+		}
+
+		// Note: comma-ok is required, nil value of interface type A is not assertable
+		val, _ := x.Interface().(A)
+		return val, chosen - 1, true
+	}
+}
@@ -0,0 +1,136 @@
+//
+// Copyright (C) 2022 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/golem
+//
+
+package pipe_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fogfish/golem/pipe/v2"
+	"github.com/fogfish/it/v2"
+)
+
+func TestJoinPriority(t *testing.T) {
+	t.Run("Strict", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+
+		a := pipe.Seq(1, 2, 3)
+		b := pipe.Seq(4, 5, 6)
+		c := pipe.Seq(7, 8, 9)
+
+		out := pipe.JoinPriority(ctx, 0, c, b, a)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(out)).Equal(7, 8, 9, 4, 5, 6, 1, 2, 3),
+		)
+
+		close()
+	})
+
+	t.Run("Starvation", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+
+		a := pipe.Seq(1, 2, 3)
+		b := pipe.Seq(4, 5, 6)
+		c := pipe.Seq(7, 8, 9)
+
+		out := pipe.JoinPriority(ctx, 2, a, b, c)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(out)).Equal(1, 2, 4, 3, 5, 7, 6, 8, 9),
+		)
+
+		close()
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+
+		rcv, snd := pipe.New[int](ctx, 0)
+		b := pipe.Seq(4, 5, 6)
+
+		out := pipe.JoinPriority(ctx, 0, rcv, b)
+
+		it.Then(t).Should(
+			it.Equal(<-out, 4),
+			it.Equal(<-out, 5),
+			it.Equal(<-out, 6),
+		)
+
+		snd <- 1
+		it.Then(t).Should(
+			it.Equal(<-out, 1),
+		)
+
+		close()
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+
+		rcv, _ := pipe.New[int](ctx, 0)
+		out := pipe.JoinPriority(ctx, 0, rcv)
+		close()
+
+		select {
+		case <-out:
+		case <-time.After(100 * time.Millisecond):
+			t.Error("JoinPriority is not cancelled")
+		}
+	})
+}
+
+func TestJoinWeighted(t *testing.T) {
+	t.Run("Weighted", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+
+		a := pipe.Seq(1, 2, 3, 4)
+		b := pipe.Seq(5, 6, 7, 8)
+
+		out := pipe.JoinWeighted(ctx, []int{2, 1}, a, b)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(out)).Equal(1, 2, 5, 3, 4, 6, 7, 8),
+		)
+
+		close()
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		ctx, close := context.WithCancel(context.Background())
+
+		rcv, snd := pipe.New[int](ctx, 0)
+		b := pipe.Seq(5, 6)
+
+		out := pipe.JoinWeighted(ctx, []int{10, 1}, rcv, b)
+
+		it.Then(t).Should(
+			it.Equal(<-out, 5),
+			it.Equal(<-out, 6),
+		)
+
+		snd <- 1
+		it.Then(t).Should(
+			it.Equal(<-out, 1),
+		)
+
+		close()
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		defer func() {
+			it.Then(t).ShouldNot(
+				it.Nil(recover()),
+			)
+		}()
+
+		pipe.JoinWeighted(context.Background(), []int{1}, pipe.Seq(1), pipe.Seq(2))
+	})
+}
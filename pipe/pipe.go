@@ -12,6 +12,7 @@ package pipe
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"sync"
 	"time"
@@ -78,6 +79,45 @@ func Filter[A any](ctx context.Context, in <-chan A, f F[A, bool]) <-chan A {
 	return out
 }
 
+// FilterE returns a newly-allocated channel that contains only those elements x
+// of the input channel for which predicate is true. Unlike Filter, failures of
+// predicate are emitted to the error channel, following Lift/Try semantic.
+func FilterE[A any](ctx context.Context, in <-chan A, f F[A, bool]) (<-chan A, <-chan error) {
+	out := make(chan A, cap(in))
+	exx := f.errch(cap(in))
+
+	go func() {
+		defer close(out)
+		defer close(exx)
+
+		var (
+			a    A
+			take bool
+			err  error
+		)
+
+		for a = range in {
+			take, err = f.Apply(a)
+			if err != nil {
+				if !f.catch(ctx, err, exx) {
+					return
+				}
+				continue
+			}
+
+			if take {
+				select {
+				case out <- a:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, exx
+}
+
 // ForEach applies function for each message in the channel
 func ForEach[A any](ctx context.Context, in <-chan A, f F[A, A]) <-chan struct{} {
 	done := make(chan struct{})
@@ -239,6 +279,246 @@ func Partition[A any](ctx context.Context, in <-chan A, f F[A, bool]) (<-chan A,
 	return lout, rout
 }
 
+// PartitionE channel into two channels according to predicate. Unlike Partition,
+// failures of predicate are emitted to the error channel, following Lift/Try semantic.
+func PartitionE[A any](ctx context.Context, in <-chan A, f F[A, bool]) (<-chan A, <-chan A, <-chan error) {
+	lout := make(chan A, cap(in))
+	rout := make(chan A, cap(in))
+	exx := f.errch(cap(in))
+
+	go func() {
+		defer close(exx)
+		defer close(rout)
+		defer close(lout)
+
+		var (
+			a   A
+			x   bool
+			err error
+		)
+
+		for a = range in {
+			x, err = f.Apply(a)
+			if err != nil {
+				if !f.catch(ctx, err, exx) {
+					return
+				}
+				continue
+			}
+
+			sel := rout
+			if x {
+				sel = lout
+			}
+
+			select {
+			case sel <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return lout, rout, exx
+}
+
+// Route channel into n channels according to classifier. The element is
+// routed to the channel with index returned by classifier. Elements classified
+// outside of range [0, n) or failed by classifier are routed to the default channel,
+// use RouteE to observe failures. With n = 0, all elements are routed to
+// the default channel. It panics if n is negative.
+func Route[A any](ctx context.Context, in <-chan A, n int, f F[A, int]) ([]<-chan A, <-chan A) {
+	if n < 0 {
+		panic(fmt.Errorf("invalid number of channels: %d", n))
+	}
+
+	outs := make([]chan A, n)
+	for i := range outs {
+		outs[i] = make(chan A, cap(in))
+	}
+	dout := make(chan A, cap(in))
+
+	go func() {
+		defer close(dout)
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		sel := func(x int, err error) chan<- A {
+			if err == nil && x >= 0 && x < n {
+				return outs[x]
+			}
+			return dout
+		}
+
+		var a A
+		for a = range in {
+			select {
+			case sel(f.Apply(a)) <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	eg := make([]<-chan A, n)
+	for i, out := range outs {
+		eg[i] = out
+	}
+
+	return eg, dout
+}
+
+// RouteE channel into n channels according to classifier. Unlike Route,
+// failures of classifier are emitted to the error channel, following Lift/Try
+// semantic. Elements classified outside of range [0, n) are routed to
+// the default channel. It panics if n is negative.
+func RouteE[A any](ctx context.Context, in <-chan A, n int, f F[A, int]) ([]<-chan A, <-chan A, <-chan error) {
+	if n < 0 {
+		panic(fmt.Errorf("invalid number of channels: %d", n))
+	}
+
+	outs := make([]chan A, n)
+	for i := range outs {
+		outs[i] = make(chan A, cap(in))
+	}
+	dout := make(chan A, cap(in))
+	exx := f.errch(cap(in))
+
+	sel := func(x int) chan A {
+		if x >= 0 && x < n {
+			return outs[x]
+		}
+		return dout
+	}
+
+	eg := make([]<-chan A, n)
+	for i, out := range outs {
+		eg[i] = out
+	}
+
+	done := func() {
+		for _, out := range outs {
+			close(out)
+		}
+		close(dout)
+		close(exx)
+	}
+
+	route(ctx, in, f, sel, exx, done)
+	return eg, dout, exx
+}
+
+// RouteBy channel into channels identified by keys according to classifier.
+// Elements classified with unknown key or failed by classifier are routed to
+// the default channel, use RouteByE to observe failures.
+func RouteBy[A any, K comparable](ctx context.Context, in <-chan A, keys []K, f F[A, K]) (map[K]<-chan A, <-chan A) {
+	outs := make(map[K]chan A, len(keys))
+	for _, k := range keys {
+		outs[k] = make(chan A, cap(in))
+	}
+	dout := make(chan A, cap(in))
+
+	go func() {
+		defer close(dout)
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		sel := func(k K, err error) chan<- A {
+			if out, has := outs[k]; has && err == nil {
+				return out
+			}
+			return dout
+		}
+
+		var a A
+		for a = range in {
+			select {
+			case sel(f.Apply(a)) <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	eg := make(map[K]<-chan A, len(outs))
+	for k, out := range outs {
+		eg[k] = out
+	}
+
+	return eg, dout
+}
+
+// RouteByE channel into channels identified by keys according to classifier.
+// Unlike RouteBy, failures of classifier are emitted to the error channel,
+// following Lift/Try semantic. Elements classified with unknown key are routed
+// to the default channel.
+func RouteByE[A any, K comparable](ctx context.Context, in <-chan A, keys []K, f F[A, K]) (map[K]<-chan A, <-chan A, <-chan error) {
+	outs := make(map[K]chan A, len(keys))
+	for _, k := range keys {
+		outs[k] = make(chan A, cap(in))
+	}
+	dout := make(chan A, cap(in))
+	exx := f.errch(cap(in))
+
+	sel := func(k K) chan A {
+		if out, has := outs[k]; has {
+			return out
+		}
+		return dout
+	}
+
+	eg := make(map[K]<-chan A, len(outs))
+	for k, out := range outs {
+		eg[k] = out
+	}
+
+	done := func() {
+		for _, out := range outs {
+			close(out)
+		}
+		close(dout)
+		close(exx)
+	}
+
+	route(ctx, in, f, sel, exx, done)
+	return eg, dout, exx
+}
+
+// routes elements of channel using classifier, failures are emitted to exx
+func route[A any, K comparable](ctx context.Context, in <-chan A, f F[A, K], sel func(K) chan A, exx chan error, done func()) {
+	go func() {
+		defer done()
+
+		var (
+			a   A
+			k   K
+			err error
+		)
+
+		for a = range in {
+			k, err = f.Apply(a)
+			if err != nil {
+				if !f.catch(ctx, err, exx) {
+					return
+				}
+				continue
+			}
+
+			select {
+			case sel(k) <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
 // Unfold is the fundamental recursive constructor, it applies a function to
 // each previous seed element in turn to determine the next element.
 func Unfold[A any](ctx context.Context, cap int, seed A, f F[A, A]) (<-chan A, <-chan error) {
@@ -352,6 +632,47 @@ func TakeWhile[A any](ctx context.Context, in <-chan A, f F[A, bool]) <-chan A {
 	return out
 }
 
+// TakeWhileE returns a newly-allocated channel that contains those elements from
+// channel while predicate returns true. Unlike TakeWhile, failures of predicate
+// are emitted to the error channel, following Lift/Try semantic.
+func TakeWhileE[A any](ctx context.Context, in <-chan A, f F[A, bool]) (<-chan A, <-chan error) {
+	out := make(chan A, cap(in))
+	exx := f.errch(cap(in))
+
+	go func() {
+		defer close(out)
+		defer close(exx)
+
+		var (
+			a    A
+			take bool
+			err  error
+		)
+
+		for a = range in {
+			take, err = f.Apply(a)
+			if err != nil {
+				if !f.catch(ctx, err, exx) {
+					return
+				}
+				continue
+			}
+
+			if !take {
+				return
+			}
+
+			select {
+			case out <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, exx
+}
+
 // Throttling the channel to ops per time interval.
 func Throttling[A any](ctx context.Context, in <-chan A, ops int, interval time.Duration) <-chan A {
 	out := make(chan A, cap(in))
@@ -90,6 +90,57 @@ func TestFilter(t *testing.T) {
 	close()
 }
 
+func TestFilterE(t *testing.T) {
+	t.Run("FilterE", func(t *testing.T) {
+		fun := pipe.Pure(func(x int) bool { return x%2 == 1 })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5)
+		out := pipe.StdErr(pipe.FilterE(ctx, seq, fun))
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(out)).Equal(1, 3, 5),
+		)
+
+		close()
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		fun := pipe.Lift(func(x int) (bool, error) { return false, fmt.Errorf("fail") })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5)
+		out, exx := pipe.FilterE(ctx, seq, fun)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return <-exx }).Contain("fail"),
+			it.Equal(len(pipe.ToSeq(out)), 0),
+		)
+
+		close()
+	})
+
+	t.Run("Try", func(t *testing.T) {
+		fun := pipe.Try(func(x int) (bool, error) {
+			if x%2 == 0 {
+				return false, fmt.Errorf("even")
+			}
+			return x > 1, nil
+		})
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5)
+		out, exx := pipe.FilterE(ctx, seq, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(out)).Equal(3, 5),
+			it.Seq(pipe.ToSeq(exx)).Equal(fmt.Errorf("even"), fmt.Errorf("even")),
+		)
+
+		close()
+	})
+}
+
 func TestForEach(t *testing.T) {
 	n := 0
 	fun := pipe.Pure(func(x int) int {
@@ -252,6 +303,215 @@ func TestPartition(t *testing.T) {
 	close()
 }
 
+func TestPartitionE(t *testing.T) {
+	t.Run("PartitionE", func(t *testing.T) {
+		fun := pipe.Pure(func(x int) bool { return x%2 == 1 })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5)
+		lo, ro, exx := pipe.PartitionE(ctx, seq, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(lo)).Equal(1, 3, 5),
+			it.Seq(pipe.ToSeq(ro)).Equal(2, 4),
+			it.Equal(len(pipe.ToSeq(exx)), 0),
+		)
+
+		close()
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		fun := pipe.Lift(func(x int) (bool, error) { return false, fmt.Errorf("fail") })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5)
+		lo, ro, exx := pipe.PartitionE(ctx, seq, fun)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return <-exx }).Contain("fail"),
+			it.Equal(len(pipe.ToSeq(lo)), 0),
+			it.Equal(len(pipe.ToSeq(ro)), 0),
+		)
+
+		close()
+	})
+
+	t.Run("Try", func(t *testing.T) {
+		fun := pipe.Try(func(x int) (bool, error) {
+			if x == 3 {
+				return false, fmt.Errorf("three")
+			}
+			return x%2 == 1, nil
+		})
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5)
+		lo, ro, exx := pipe.PartitionE(ctx, seq, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(lo)).Equal(1, 5),
+			it.Seq(pipe.ToSeq(ro)).Equal(2, 4),
+			it.Seq(pipe.ToSeq(exx)).Equal(fmt.Errorf("three")),
+		)
+
+		close()
+	})
+}
+
+func TestRoute(t *testing.T) {
+	t.Run("Route", func(t *testing.T) {
+		fun := pipe.Pure(func(x int) int { return x % 4 })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5, 6, 7)
+		outs, def := pipe.Route(ctx, seq, 3, fun)
+
+		it.Then(t).Should(
+			it.Equal(len(outs), 3),
+			it.Seq(pipe.ToSeq(outs[0])).Equal(4),
+			it.Seq(pipe.ToSeq(outs[1])).Equal(1, 5),
+			it.Seq(pipe.ToSeq(outs[2])).Equal(2, 6),
+			it.Seq(pipe.ToSeq(def)).Equal(3, 7),
+		)
+
+		close()
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		fun := pipe.Lift(func(x int) (int, error) { return 0, fmt.Errorf("fail") })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3)
+		outs, def := pipe.Route(ctx, seq, 1, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(def)).Equal(1, 2, 3),
+			it.Equal(len(pipe.ToSeq(outs[0])), 0),
+		)
+
+		close()
+	})
+
+	t.Run("OutOfRange", func(t *testing.T) {
+		fun := pipe.Pure(func(x int) int { return x - 2 })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5)
+		outs, def := pipe.Route(ctx, seq, 2, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(outs[0])).Equal(2),
+			it.Seq(pipe.ToSeq(outs[1])).Equal(3),
+			it.Seq(pipe.ToSeq(def)).Equal(1, 4, 5),
+		)
+
+		close()
+	})
+
+	t.Run("Zero", func(t *testing.T) {
+		fun := pipe.Pure(func(x int) int { return 0 })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3)
+		outs, def := pipe.Route(ctx, seq, 0, fun)
+
+		it.Then(t).Should(
+			it.Equal(len(outs), 0),
+			it.Seq(pipe.ToSeq(def)).Equal(1, 2, 3),
+		)
+
+		close()
+	})
+
+	t.Run("Negative", func(t *testing.T) {
+		fun := pipe.Pure(func(x int) int { return 0 })
+
+		it.Then(t).Should(
+			it.Fail(func() { pipe.Route(context.Background(), pipe.Seq(1), -1, fun) }).
+				Contain("invalid number of channels"),
+		)
+	})
+
+	t.Run("RouteBy", func(t *testing.T) {
+		fun := pipe.Pure(func(x string) string { return x[:1] })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq("a1", "b1", "c1", "a2", "b2")
+		outs, def := pipe.RouteBy(ctx, seq, []string{"a", "b"}, fun)
+
+		it.Then(t).Should(
+			it.Equal(len(outs), 2),
+			it.Seq(pipe.ToSeq(outs["a"])).Equal("a1", "a2"),
+			it.Seq(pipe.ToSeq(outs["b"])).Equal("b1", "b2"),
+			it.Seq(pipe.ToSeq(def)).Equal("c1"),
+		)
+
+		close()
+	})
+}
+
+func TestRouteE(t *testing.T) {
+	t.Run("RouteE", func(t *testing.T) {
+		fun := pipe.Try(func(x int) (int, error) {
+			if x == 3 {
+				return 0, fmt.Errorf("three")
+			}
+			return x % 2, nil
+		})
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5, -1)
+		outs, def, exx := pipe.RouteE(ctx, seq, 2, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(outs[0])).Equal(2, 4),
+			it.Seq(pipe.ToSeq(outs[1])).Equal(1, 5),
+			it.Seq(pipe.ToSeq(def)).Equal(-1),
+			it.Seq(pipe.ToSeq(exx)).Equal(fmt.Errorf("three")),
+		)
+
+		close()
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		fun := pipe.Lift(func(x int) (int, error) { return 0, fmt.Errorf("fail") })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3)
+		outs, def, exx := pipe.RouteE(ctx, seq, 1, fun)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return <-exx }).Contain("fail"),
+			it.Equal(len(pipe.ToSeq(outs[0])), 0),
+			it.Equal(len(pipe.ToSeq(def)), 0),
+		)
+
+		close()
+	})
+
+	t.Run("RouteByE", func(t *testing.T) {
+		fun := pipe.Try(func(x string) (string, error) {
+			if x == "" {
+				return "", fmt.Errorf("empty")
+			}
+			return x[:1], nil
+		})
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq("a1", "b1", "", "c1", "a2")
+		outs, def, exx := pipe.RouteByE(ctx, seq, []string{"a", "b"}, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(outs["a"])).Equal("a1", "a2"),
+			it.Seq(pipe.ToSeq(outs["b"])).Equal("b1"),
+			it.Seq(pipe.ToSeq(def)).Equal("c1"),
+			it.Seq(pipe.ToSeq(exx)).Equal(fmt.Errorf("empty")),
+		)
+
+		close()
+	})
+}
+
 func TestUnfold(t *testing.T) {
 	t.Run("Unfold", func(t *testing.T) {
 		fun := pipe.Pure(func(x int) int { return x + 1 })
@@ -329,6 +589,62 @@ func TestTakeWhile(t *testing.T) {
 	close()
 }
 
+func TestTakeWhileE(t *testing.T) {
+	t.Run("TakeWhileE", func(t *testing.T) {
+		fun := pipe.Pure(func(x int) bool { return x < 4 })
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5, 6)
+		out := pipe.StdErr(pipe.TakeWhileE(ctx, seq, fun))
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(out)).Equal(1, 2, 3),
+		)
+
+		close()
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		fun := pipe.Lift(func(x int) (bool, error) {
+			if x == 2 {
+				return false, fmt.Errorf("fail")
+			}
+			return true, nil
+		})
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5, 6)
+		out, exx := pipe.TakeWhileE(ctx, seq, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(out)).Equal(1),
+			it.Seq(pipe.ToSeq(exx)).Equal(fmt.Errorf("fail")),
+		)
+
+		close()
+	})
+
+	t.Run("Try", func(t *testing.T) {
+		fun := pipe.Try(func(x int) (bool, error) {
+			if x == 2 {
+				return false, fmt.Errorf("fail")
+			}
+			return x < 4, nil
+		})
+
+		ctx, close := context.WithCancel(context.Background())
+		seq := pipe.Seq(1, 2, 3, 4, 5, 6)
+		out, exx := pipe.TakeWhileE(ctx, seq, fun)
+
+		it.Then(t).Should(
+			it.Seq(pipe.ToSeq(out)).Equal(1, 3),
+			it.Seq(pipe.ToSeq(exx)).Equal(fmt.Errorf("fail")),
+		)
+
+		close()
+	})
+}
+
 func TestThrottling(t *testing.T) {
 	ctx, close := context.WithCancel(context.Background())
 	seq := pipe.Seq(1, 2, 3, 4, 5, 6, 7, 8, 9, 0)
@@ -8,4 +8,4 @@
 
 package pipe
 
-const Version = "pipe/v2.1.0"
+const Version = "pipe/v2.2.0"